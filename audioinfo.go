@@ -0,0 +1,74 @@
+package eiscp
+
+import (
+	"strings"
+)
+
+// AudioInformation is the receiver's report on the audio signal (IFA).
+// Older models send fewer fields, anything missing is left empty.
+type AudioInformation struct {
+	InputPort               string // e.g. "HDMI 1"
+	InputFormat             string // e.g. "Dolby TrueHD", "PCM"
+	SamplingFrequency       string // e.g. "48 kHz"
+	InputChannels           string // e.g. "5.1ch"
+	ListeningMode           string // e.g. "Dolby Atmos"
+	OutputChannels          string // e.g. "7.1.2ch"
+	OutputSamplingFrequency string
+	PQLS                    string
+	AutoPhaseControlDelay   string
+	AutoPhaseControlPhase   string
+	UpmixMode               string
+	Raw                     string // the whole reply, for fields newer models add
+}
+
+// parseIFA splits an IFA reply, e.g. "HDMI 1,Dolby TrueHD,48 kHz,5.1ch,Dolby Atmos,7.1.2ch,"
+func parseIFA(r string) *AudioInformation {
+	ai := AudioInformation{Raw: r}
+	fields := []*string{
+		&ai.InputPort,
+		&ai.InputFormat,
+		&ai.SamplingFrequency,
+		&ai.InputChannels,
+		&ai.ListeningMode,
+		&ai.OutputChannels,
+		&ai.OutputSamplingFrequency,
+		&ai.PQLS,
+		&ai.AutoPhaseControlDelay,
+		&ai.AutoPhaseControlPhase,
+		&ai.UpmixMode,
+	}
+	for i, v := range strings.Split(r, ",") {
+		if i == len(fields) {
+			break
+		}
+		*fields[i] = strings.TrimSpace(v)
+	}
+	return &ai
+}
+
+// String summarises the signal for display, e.g. "Dolby Atmos 7.1.2 @ 48 kHz"
+func (ai *AudioInformation) String() string {
+	var parts []string
+	if ai.ListeningMode != "" {
+		parts = append(parts, ai.ListeningMode)
+	} else if ai.InputFormat != "" {
+		parts = append(parts, ai.InputFormat)
+	}
+
+	channels := ai.OutputChannels
+	if channels == "" {
+		channels = ai.InputChannels
+	}
+	if channels != "" {
+		parts = append(parts, strings.TrimSpace(strings.TrimSuffix(channels, "ch")))
+	}
+
+	if ai.SamplingFrequency != "" {
+		parts = append(parts, "@", ai.SamplingFrequency)
+	}
+	if len(parts) == 0 {
+		// e.g. an analog input, which has no format to report
+		return ai.InputPort
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,94 @@
+package eiscp
+
+import (
+	"testing"
+)
+
+func TestParseIFA(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   AudioInformation
+		String string
+	}{
+		{
+			name: "atmos",
+			raw:  "HDMI 1,Dolby TrueHD,48 kHz,5.1ch,Dolby Atmos,7.1.2ch,",
+			want: AudioInformation{
+				InputPort:         "HDMI 1",
+				InputFormat:       "Dolby TrueHD",
+				SamplingFrequency: "48 kHz",
+				InputChannels:     "5.1ch",
+				ListeningMode:     "Dolby Atmos",
+				OutputChannels:    "7.1.2ch",
+			},
+			String: "Dolby Atmos 7.1.2 @ 48 kHz",
+		},
+		{
+			name: "spaced channels",
+			raw:  "HDMI 1,Dolby TrueHD,48 kHz,5.1 ch,Dolby Atmos,7.1.2 ch,",
+			want: AudioInformation{
+				InputPort:         "HDMI 1",
+				InputFormat:       "Dolby TrueHD",
+				SamplingFrequency: "48 kHz",
+				InputChannels:     "5.1 ch",
+				ListeningMode:     "Dolby Atmos",
+				OutputChannels:    "7.1.2 ch",
+			},
+			String: "Dolby Atmos 7.1.2 @ 48 kHz",
+		},
+		{
+			name: "extended",
+			raw:  "HDMI 2,PCM,44.1 kHz,2.0ch,Stereo,2.0ch,44.1 kHz,Off,0ms,Normal,No,Extra",
+			want: AudioInformation{
+				InputPort:               "HDMI 2",
+				InputFormat:             "PCM",
+				SamplingFrequency:       "44.1 kHz",
+				InputChannels:           "2.0ch",
+				ListeningMode:           "Stereo",
+				OutputChannels:          "2.0ch",
+				OutputSamplingFrequency: "44.1 kHz",
+				PQLS:                    "Off",
+				AutoPhaseControlDelay:   "0ms",
+				AutoPhaseControlPhase:   "Normal",
+				UpmixMode:               "No",
+			},
+			String: "Stereo 2.0 @ 44.1 kHz",
+		},
+		{
+			name: "legacy short",
+			raw:  "COAXIAL,PCM,48 kHz",
+			want: AudioInformation{
+				InputPort:         "COAXIAL",
+				InputFormat:       "PCM",
+				SamplingFrequency: "48 kHz",
+			},
+			String: "PCM @ 48 kHz",
+		},
+		{
+			name:   "analog",
+			raw:    "Analog,,,",
+			want:   AudioInformation{InputPort: "Analog"},
+			String: "Analog",
+		},
+		{
+			name:   "empty",
+			raw:    "",
+			want:   AudioInformation{},
+			String: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseIFA(tt.raw)
+			tt.want.Raw = tt.raw
+			if *got != tt.want {
+				t.Errorf("parseIFA(%q) = %+v, want %+v", tt.raw, *got, tt.want)
+			}
+			if s := got.String(); s != tt.String {
+				t.Errorf("String() = %q, want %q", s, tt.String)
+			}
+		})
+	}
+}
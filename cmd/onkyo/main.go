@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudkucooland/go-onkyo"
 )
 
 func main() {
 	var command, value string
-	host := flag.String("h", "", "Onkyo host")
+	host := flag.String("h", "", "Onkyo host: address, MAC or friendly name")
+	serial := flag.String("s", "", "serial device (e.g. /dev/ttyUSB0), used instead of the network")
 	// verbose := flag.Bool("v", false, "verbose")
 	flag.Parse()
 
@@ -26,11 +31,43 @@ func main() {
 		value = args[1]
 	}
 
-	dev, err := eiscp.NewReceiver(*host, false)
+	// discover doesn't need a connection
+	if command == "discover" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		devices, err := eiscp.DiscoverAll(ctx)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		for _, dd := range devices {
+			fmt.Printf("%s\t%s\t%s\t%s\n", dd.Address(), dd.MAC, dd.Model, dd.Name)
+		}
+		return
+	}
+
+	var dev *eiscp.Device
+	var err error
+	if *serial != "" {
+		dev, err = eiscp.OpenSerialReceiver(*serial, false)
+	} else {
+		dev, err = eiscp.NewReceiver(*host, false)
+	}
 	if err != nil {
 		panic(err)
 	}
 	defer dev.Close()
+	switch command {
+	case "zone2", "zone3", "zone4":
+		n, _ := strconv.Atoi(command[4:])
+		z, err := dev.Zone(n)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		zoneCommand(z, args[1:])
+		return
+	}
 	if value == "" {
 		switch command {
 		case "details":
@@ -40,6 +77,12 @@ func main() {
 				return
 			}
 			fmt.Printf("%+v\n", nri)
+		case "state":
+			if err := dev.Refresh(); err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("%+v\n", dev.State())
 		case "power":
 			resp, err := dev.GetPower()
 			if err != nil {
@@ -139,12 +182,80 @@ func main() {
 				panic(err)
 			}
 			fmt.Printf("listening mode: %s\n", s)
+		case "tone":
+			for _, ch := range eiscp.ToneChannels {
+				t, err := dev.GetTone(ch)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("%s: bass: %d treble: %d\n", ch, t.Bass, t.Treble)
+			}
+		case "tuner":
+			f, err := dev.Tuner().Get()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Println(f)
+		case "rds":
+			ts, err := dev.Tuner().Status()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("%s\tstation: %s\ttext: %s\ttype: %s\n", ts.Frequency, ts.PSName, ts.RadioText, ts.ProgramType)
+		case "xm", "sirius", "hd":
+			info, err := radioService(dev, command).Info()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("channel: %d %s\t%s - %s\n", info.Channel, info.ChannelName, info.Artist, info.Title)
+		case "dab":
+			name, err := dev.DAB().StationName()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			p, _ := dev.DAB().GetPreset()
+			ensemble, _ := dev.DAB().EnsembleName()
+			fmt.Printf("preset: %d\t%s\tensemble: %s\n", p, name, ensemble)
+		case "audio":
+			ai, err := dev.GetAudioInformation()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Println(ai)
+			fmt.Printf("%+v\n", *ai)
+		case "presets":
+			if err := dev.Tuner().ExportPresets(os.Stdout); err != nil {
+				fmt.Println(err.Error())
+			}
+		case "levels":
+			for _, lc := range []eiscp.LevelControl{eiscp.LevelSubwoofer, eiscp.LevelSubwoofer2, eiscp.LevelCenter} {
+				db, err := dev.GetLevel(lc)
+				if err != nil {
+					continue
+				}
+				fmt.Printf("%s: %+.1f dB\n", lc, db)
+			}
+			levels, err := dev.GetChannelLevels()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			for _, ch := range eiscp.Channels {
+				if db, ok := levels[ch]; ok {
+					fmt.Printf("%s: %+.1f dB\n", ch, db)
+				}
+			}
 		case "listeningmodes":
 			for k, v := range eiscp.ListeningModes {
 				fmt.Printf("%s: %s\n", k, v)
 			}
 		case "help":
-			fmt.Println("get commands: test, nms, temp, preset, nowplaying, network, source, volume, power, details, listeningmode, listeningmodes")
+			fmt.Println("get commands: discover, state, test, nms, temp, preset, nowplaying, network, source, volume, power, details, listeningmode, listeningmodes, audio, tone, levels, tuner, rds, presets, xm, sirius, hd, dab, zone2, zone3, zone4")
 		default:
 			if len(command) != 3 {
 				fmt.Println("usage: onkyo [command|CMD] [value]")
@@ -229,16 +340,291 @@ func main() {
 				panic(err)
 			}
 			fmt.Printf("listening mode: %s\n", s)
+		case "tune": // e.g. 101.1 (MHz), 810 (kHz), up, down, seekup, seekdown
+			t := dev.Tuner()
+			var f *eiscp.Frequency
+			switch value {
+			case "up", "down":
+				f, err = t.Step(value == "up")
+			case "seekup", "seekdown":
+				f, err = t.Seek(value == "seekup")
+			default:
+				v, perr := strconv.ParseFloat(value, 64)
+				if perr != nil {
+					panic(perr)
+				}
+				if v < 200 {
+					f, err = t.SetFrequency(eiscp.FM(v))
+				} else {
+					f, err = t.SetFrequency(eiscp.AM(int(v)))
+				}
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Println(f)
+		case "rds": // what the display shows: text, pty or tp
+			modes := map[string]eiscp.RDSMode{"text": eiscp.RDSRadioText, "pty": eiscp.RDSProgramType, "tp": eiscp.RDSTrafficProgram}
+			mode, ok := modes[value]
+			if !ok {
+				fmt.Println("usage: onkyo rds text|pty|tp")
+				return
+			}
+			if err := dev.Tuner().SetRDSMode(mode); err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("rds: %s\n", value)
+		case "xm", "sirius", "hd": // channel number, up or down
+			rs := radioService(dev, command)
+			var ch int
+			switch value {
+			case "up":
+				ch, err = rs.ChannelUp()
+			case "down":
+				ch, err = rs.ChannelDown()
+			default:
+				n, perr := strconv.Atoi(value)
+				if perr != nil {
+					panic(perr)
+				}
+				ch, err = rs.SetChannel(n)
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("channel: %d\n", ch)
+		case "dab": // preset number, up or down
+			var p int
+			switch value {
+			case "up":
+				p, err = dev.DAB().PresetUp()
+			case "down":
+				p, err = dev.DAB().PresetDown()
+			default:
+				n, perr := strconv.Atoi(value)
+				if perr != nil {
+					panic(perr)
+				}
+				p, err = dev.DAB().SelectPreset(n)
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("preset: %d\n", p)
+		case "station": // select a preset by name
+			n, err := dev.Tuner().SelectPresetByName(value)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("preset: %d\n", n)
+		case "store":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(err)
+			}
+			if err := dev.Tuner().StorePreset(n); err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("stored: %d\n", n)
+		case "importpresets": // a file written by "onkyo presets"
+			f, err := os.Open(value)
+			if err != nil {
+				panic(err)
+			}
+			defer f.Close()
+			if err := dev.Tuner().ImportPresets(f); err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Println("imported")
+		case "sw", "sw2", "center":
+			lc := map[string]eiscp.LevelControl{"sw": eiscp.LevelSubwoofer, "sw2": eiscp.LevelSubwoofer2, "center": eiscp.LevelCenter}[command]
+			var db float64
+			switch value {
+			case "up":
+				db, err = dev.LevelUp(lc)
+			case "down":
+				db, err = dev.LevelDown(lc)
+			default:
+				v, perr := strconv.ParseFloat(value, 64)
+				if perr != nil {
+					panic(perr)
+				}
+				db, err = dev.SetLevel(lc, v)
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("%s: %+.1f dB\n", lc, db)
+		case "channel": // e.g. FL=+1.5, C=up
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 {
+				fmt.Println("usage: onkyo channel CH=dB|up|down")
+				return
+			}
+			ch := eiscp.Channel(strings.ToUpper(parts[0]))
+			var levels eiscp.ChannelLevels
+			switch parts[1] {
+			case "up":
+				levels, err = dev.ChannelLevelUp(ch)
+			case "down":
+				levels, err = dev.ChannelLevelDown(ch)
+			default:
+				v, perr := strconv.ParseFloat(parts[1], 64)
+				if perr != nil {
+					panic(perr)
+				}
+				levels, err = dev.SetChannelLevel(ch, v)
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("%s: %+.1f dB\n", ch, levels[ch])
+		case "bass", "treble":
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				panic(err)
+			}
+			var t *eiscp.Tone
+			if command == "bass" {
+				t, err = dev.SetBass(eiscp.ToneFront, v)
+			} else {
+				t, err = dev.SetTreble(eiscp.ToneFront, v)
+			}
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("front: bass: %d treble: %d\n", t.Bass, t.Treble)
 		case "help":
-			fmt.Println("set commands: select, listeningmode, nja, netsrc, netpreset, source, volume, power")
+			fmt.Println("set commands: select, listeningmode, tune, rds, station, xm, sirius, hd, dab, store, importpresets, bass, treble, sw, sw2, center, channel, nja, netsrc, netpreset, source, volume, power")
 		default:
 			mm, err := dev.SetGetAll(command, value)
+			if mm != nil {
+				for _, v := range mm.Messages {
+					fmt.Printf("reply: [%s] %s\n", v.Command, v.Response)
+				}
+			}
 			if err != nil {
-				panic(err)
+				// not every command is answered, a timeout still shows what else was heard
+				fmt.Println(err.Error())
 			}
-			for _, v := range mm.Messages {
-				fmt.Printf("reply: [%s] %s\n", v.Command, v.Response)
+		}
+	}
+}
+
+// radioService picks the XM, Sirius or HD Radio tuner by CLI name
+func radioService(dev *eiscp.Device, name string) *eiscp.RadioService {
+	switch name {
+	case "xm":
+		return dev.XM()
+	case "sirius":
+		return dev.Sirius()
+	default:
+		return dev.HDRadio()
+	}
+}
+
+// zoneCommand handles "onkyo zone2 [command] [value]", and likewise zone3 and zone4
+func zoneCommand(z *eiscp.Zone, args []string) {
+	if len(args) == 0 {
+		args = []string{"help"}
+	}
+	command := args[0]
+
+	if len(args) == 1 {
+		switch command {
+		case "power":
+			fmt.Println(z.GetPower())
+		case "volume":
+			fmt.Println(z.GetVolume())
+		case "mute":
+			fmt.Println(z.GetMute())
+		case "source":
+			src, err := z.GetSource()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Println(eiscp.SourceToName[src])
+		case "tuner":
+			fmt.Println(z.GetTuner())
+		case "preset":
+			fmt.Println(z.GetPreset())
+		case "tone":
+			t, err := z.GetTone()
+			if err != nil {
+				fmt.Println(err.Error())
+				return
 			}
+			fmt.Printf("bass: %d treble: %d\n", t.Bass, t.Treble)
+		case "balance":
+			fmt.Println(z.GetBalance())
+		default:
+			fmt.Printf("zone%d get commands: power, volume, mute, source, tuner, preset, tone, balance\n", z.Number())
+		}
+		return
+	}
+
+	value := args[1]
+	switch command {
+	case "power":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(z.SetPower(v))
+	case "volume":
+		switch value {
+		case "up":
+			fmt.Println(z.VolumeUp())
+		case "down":
+			fmt.Println(z.VolumeDown())
+		default:
+			v, err := strconv.ParseUint(value, 10, 8)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(z.SetVolume(uint8(v)))
+		}
+	case "mute":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(z.SetMute(v))
+	case "source":
+		src, ok := eiscp.SourceByName[value]
+		if !ok {
+			panic("Unknown source")
+		}
+		fmt.Println(z.SetSource(src))
+	case "tuner":
+		fmt.Println(z.SetTuner(value))
+	case "preset":
+		fmt.Println(z.SetPreset(value))
+	case "bass", "treble", "balance":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			panic(err)
+		}
+		switch command {
+		case "bass":
+			fmt.Println(z.SetBass(v))
+		case "treble":
+			fmt.Println(z.SetTreble(v))
+		default:
+			fmt.Println(z.SetBalance(v))
 		}
+	default:
+		fmt.Printf("zone%d set commands: power, volume (or up/down), mute, source, tuner, preset, bass, treble, balance\n", z.Number())
 	}
 }
@@ -1,6 +1,7 @@
 package eiscp
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"strings"
@@ -17,26 +18,50 @@ type Command struct {
 
 // SetSource - Set Onkyo source channel by friendly name
 func (d *Device) SetSource(source Source) (*Message, error) {
-	return d.SetGetOne("SLI", string(source))
+	return d.SetSourceContext(context.Background(), source)
+}
+
+// SetSourceContext is SetSource with a context for cancellation and deadlines
+func (d *Device) SetSourceContext(ctx context.Context, source Source) (*Message, error) {
+	return d.SetGetOneContext(ctx, "SLI", string(source))
 }
 
 // SetSourceByCode - Set Onkyo source channel by code
 func (d *Device) SetSourceByCode(code int) (*Message, error) {
-	return d.SetGetOne("SLI", fmt.Sprintf("%02X", code))
+	return d.SetSourceByCodeContext(context.Background(), code)
+}
+
+// SetSourceByCodeContext is SetSourceByCode with a context for cancellation and deadlines
+func (d *Device) SetSourceByCodeContext(ctx context.Context, code int) (*Message, error) {
+	return d.SetGetOneContext(ctx, "SLI", fmt.Sprintf("%02X", code))
 }
 
 // GetSource - Get Onkyo source channel. Use SourceToName to get readable name
 func (d *Device) GetSource() (string, error) {
-	msg, err := d.SetGetOne("SLI", "QSTN")
+	return d.GetSourceContext(context.Background())
+}
+
+// GetSourceContext is GetSource with a context for cancellation and deadlines
+func (d *Device) GetSourceContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "SLI", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // GetSourceByCode - Get Onkyo source channel. Use SourceToName to get readable name
 func (d *Device) GetSourceByCode() (Source, error) {
-	msg, err := d.SetGetOne("SLI", "QSTN")
+	return d.GetSourceByCodeContext(context.Background())
+}
+
+// GetSourceByCodeContext is GetSourceByCode with a context for cancellation and deadlines
+func (d *Device) GetSourceByCodeContext(ctx context.Context) (Source, error) {
+	msg, err := d.SetGetOneContext(ctx, "SLI", "QSTN")
 	if err != nil {
 		return "", err
 	}
@@ -45,152 +70,307 @@ func (d *Device) GetSourceByCode() (Source, error) {
 
 // SetPower - turn on/off Onkyo device
 func (d *Device) SetPower(on bool) (*Message, error) {
+	return d.SetPowerContext(context.Background(), on)
+}
+
+// SetPowerContext is SetPower with a context for cancellation and deadlines
+func (d *Device) SetPowerContext(ctx context.Context, on bool) (*Message, error) {
 	if on {
-		return d.SetGetOne("PWR", "01")
+		return d.SetGetOneContext(ctx, "PWR", "01")
 	}
-	return d.SetGetOne("PWR", "00")
+	return d.SetGetOneContext(ctx, "PWR", "00")
 }
 
 // GetPower - get Onkyo power state
 func (d *Device) GetPower() (bool, error) {
-	msg, err := d.SetGetOne("PWR", "QSTN")
+	return d.GetPowerContext(context.Background())
+}
+
+// GetPowerContext is GetPower with a context for cancellation and deadlines
+func (d *Device) GetPowerContext(ctx context.Context) (bool, error) {
+	msg, err := d.SetGetOneContext(ctx, "PWR", "QSTN")
 	if err != nil {
 		return false, err
 	}
-	return msg.Parsed.(bool), err
+	v, ok := msg.Parsed.(bool)
+	if !ok {
+		return false, unexpected(msg)
+	}
+	return v, nil
 }
 
 // SetVolume - set master volume in Onkyo receiver
 func (d *Device) SetVolume(level uint8) (uint8, error) {
-	msg, err := d.SetGetOne("MVL", strings.ToUpper(hex.EncodeToString([]byte{level})))
+	return d.SetVolumeContext(context.Background(), level)
+}
+
+// SetVolumeContext is SetVolume with a context for cancellation and deadlines
+func (d *Device) SetVolumeContext(ctx context.Context, level uint8) (uint8, error) {
+	msg, err := d.SetGetOneContext(ctx, "MVL", strings.ToUpper(hex.EncodeToString([]byte{level})))
 	if err != nil {
 		return uint8(0), err
 	}
-	return msg.Parsed.(uint8), err
+	v, ok := msg.Parsed.(uint8)
+	if !ok {
+		return uint8(0), unexpected(msg)
+	}
+	return v, nil
 }
 
 // GetVolume - get master volume in Onkyo receiver
 func (d *Device) GetVolume() (uint8, error) {
-	msg, err := d.SetGetOne("MVL", "QSTN")
+	return d.GetVolumeContext(context.Background())
+}
+
+// GetVolumeContext is GetVolume with a context for cancellation and deadlines
+func (d *Device) GetVolumeContext(ctx context.Context) (uint8, error) {
+	msg, err := d.SetGetOneContext(ctx, "MVL", "QSTN")
 	if err != nil {
 		return 0, err
 	}
-	return msg.Parsed.(uint8), err
+	v, ok := msg.Parsed.(uint8)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetMute() (bool, error) {
-	msg, err := d.SetGetOne("AMT", "QSTN")
+	return d.GetMuteContext(context.Background())
+}
+
+// GetMuteContext is GetMute with a context for cancellation and deadlines
+func (d *Device) GetMuteContext(ctx context.Context) (bool, error) {
+	msg, err := d.SetGetOneContext(ctx, "AMT", "QSTN")
 	if err != nil {
 		return false, err
 	}
-	return msg.Parsed.(bool), err
+	v, ok := msg.Parsed.(bool)
+	if !ok {
+		return false, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) SetMute(mute bool) (bool, error) {
+	return d.SetMuteContext(context.Background(), mute)
+}
+
+// SetMuteContext is SetMute with a context for cancellation and deadlines
+func (d *Device) SetMuteContext(ctx context.Context, mute bool) (bool, error) {
 	state := "00"
 	if mute {
 		state = "01"
 	}
-	msg, err := d.SetGetOne("AMT", state)
+	msg, err := d.SetGetOneContext(ctx, "AMT", state)
 	if err != nil {
 		return false, err
 	}
-	return msg.Parsed.(bool), err
+	v, ok := msg.Parsed.(bool)
+	if !ok {
+		return false, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetDetails() (*NRI, error) {
-	msg, err := d.SetGetOne("NRI", "QSTN")
+	return d.GetDetailsContext(context.Background())
+}
+
+// GetDetailsContext is GetDetails with a context for cancellation and deadlines
+func (d *Device) GetDetailsContext(ctx context.Context) (*NRI, error) {
+	msg, err := d.SetGetOneContext(ctx, "NRI", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NRI), nil
+	v, ok := msg.Parsed.(*NRI)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetDisplayMode() (string, error) {
-	msg, err := d.SetGetOne("DIF", "QSTN")
+	return d.GetDisplayModeContext(context.Background())
+}
+
+// GetDisplayModeContext is GetDisplayMode with a context for cancellation and deadlines
+func (d *Device) GetDisplayModeContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "DIF", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
-func (d *Device) GetAudioInformation() (string, error) {
-	msg, err := d.SetGetOne("IFA", "QSTN")
+// GetAudioInformation gets the input signal and how it is being played
+func (d *Device) GetAudioInformation() (*AudioInformation, error) {
+	return d.GetAudioInformationContext(context.Background())
+}
+
+// GetAudioInformationContext is GetAudioInformation with a context for cancellation and deadlines
+func (d *Device) GetAudioInformationContext(ctx context.Context) (*AudioInformation, error) {
+	msg, err := d.SetGetOneContext(ctx, "IFA", "QSTN")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(*AudioInformation)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetDimmer() (string, error) {
-	msg, err := d.SetGetOne("DIM", "QSTN")
+	return d.GetDimmerContext(context.Background())
+}
+
+// GetDimmerContext is GetDimmer with a context for cancellation and deadlines
+func (d *Device) GetDimmerContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "DIM", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetVideoInformation() (string, error) {
-	msg, err := d.SetGetOne("IFV", "QSTN")
+	return d.GetVideoInformationContext(context.Background())
+}
+
+// GetVideoInformationContext is GetVideoInformation with a context for cancellation and deadlines
+func (d *Device) GetVideoInformationContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "IFV", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // hangs
 func (d *Device) GetFLInformation() (string, error) {
-	msg, err := d.SetGetOne("FLD", "QSTN")
+	return d.GetFLInformationContext(context.Background())
+}
+
+// GetFLInformationContext is GetFLInformation with a context for cancellation and deadlines
+func (d *Device) GetFLInformationContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "FLD", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetMonitorResolution() (string, error) {
-	msg, err := d.SetGetOne("RES", "QSTN")
+	return d.GetMonitorResolutionContext(context.Background())
+}
+
+// GetMonitorResolutionContext is GetMonitorResolution with a context for cancellation and deadlines
+func (d *Device) GetMonitorResolutionContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "RES", "QSTN")
 	if err != nil {
 		return "unknown", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "unknown", unexpected(msg)
+	}
+	return v, nil
 }
 
 // hangs
 func (d *Device) GetHDMIOut() (string, error) {
-	msg, err := d.SetGetOne("HOI", "QSTN")
+	return d.GetHDMIOutContext(context.Background())
+}
+
+// GetHDMIOutContext is GetHDMIOut with a context for cancellation and deadlines
+func (d *Device) GetHDMIOutContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "HOI", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // hangs
 func (d *Device) GetISF() (string, error) {
-	msg, err := d.SetGetOne("ISF", "QSTN")
+	return d.GetISFContext(context.Background())
+}
+
+// GetISFContext is GetISF with a context for cancellation and deadlines
+func (d *Device) GetISFContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "ISF", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // hangs
 func (d *Device) GetWideVideoMode() (string, error) {
-	msg, err := d.SetGetOne("VWM", "QSTN")
+	return d.GetWideVideoModeContext(context.Background())
+}
+
+// GetWideVideoModeContext is GetWideVideoMode with a context for cancellation and deadlines
+func (d *Device) GetWideVideoModeContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "VWM", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetListeningMode() (string, error) {
-	msg, err := d.SetGetOne("LMD", "QSTN")
+	return d.GetListeningModeContext(context.Background())
+}
+
+// GetListeningModeContext is GetListeningMode with a context for cancellation and deadlines
+func (d *Device) GetListeningModeContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "LMD", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) SetListeningMode(code string) (string, error) {
+	return d.SetListeningModeContext(context.Background(), code)
+}
+
+// SetListeningModeContext is SetListeningMode with a context for cancellation and deadlines
+func (d *Device) SetListeningModeContext(ctx context.Context, code string) (string, error) {
 	if len(code) != 2 {
 		for k, v := range ListeningModes {
 			if v == code {
@@ -199,69 +379,123 @@ func (d *Device) SetListeningMode(code string) (string, error) {
 			}
 		}
 	}
-	msg, err := d.SetGetOne("LMD", code)
+	msg, err := d.SetGetOneContext(ctx, "LMD", code)
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkJacketArt() (string, error) {
-	msg, err := d.SetGetOne("NJA", "QSTN")
+	return d.GetNetworkJacketArtContext(context.Background())
+}
+
+// GetNetworkJacketArtContext is GetNetworkJacketArt with a context for cancellation and deadlines
+func (d *Device) GetNetworkJacketArtContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "NJA", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // this should return a bool...
 func (d *Device) SetNetworkJacketArt(s bool) (bool, error) {
+	return d.SetNetworkJacketArtContext(context.Background(), s)
+}
+
+// SetNetworkJacketArtContext is SetNetworkJacketArt with a context for cancellation and deadlines
+func (d *Device) SetNetworkJacketArtContext(ctx context.Context, s bool) (bool, error) {
 	state := "DIS"
 	if s {
 		state = "ENA"
 	}
-	err := d.SetOnly("NJA", state)
+	err := d.SetOnlyContext(ctx, "NJA", state)
 	if err != nil {
 		return false, err
 	}
 
-	msg, err := d.SetGetOne("NJA", "QSTN")
+	msg, err := d.SetGetOneContext(ctx, "NJA", "QSTN")
 	if err != nil {
 		return false, err
 	}
 	if msg.Parsed == nil {
 		return false, nil
 	}
-	return msg.Parsed.(bool), nil
+	v, ok := msg.Parsed.(bool)
+	if !ok {
+		return false, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkTitle() (*NLT, error) {
-	msg, err := d.SetGetOne("NLT", "QSTN")
+	return d.GetNetworkTitleContext(context.Background())
+}
+
+// GetNetworkTitleContext is GetNetworkTitle with a context for cancellation and deadlines
+func (d *Device) GetNetworkTitleContext(ctx context.Context) (*NLT, error) {
+	msg, err := d.SetGetOneContext(ctx, "NLT", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NLT), nil
+	v, ok := msg.Parsed.(*NLT)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkTitleName() (string, error) {
-	msg, err := d.SetGetOne("NTI", "QSTN")
+	return d.GetNetworkTitleNameContext(context.Background())
+}
+
+// GetNetworkTitleNameContext is GetNetworkTitleName with a context for cancellation and deadlines
+func (d *Device) GetNetworkTitleNameContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "NTI", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkListInfo() (*NLS, error) {
-	msg, err := d.SetGetOne("NLS", "QSTN")
+	return d.GetNetworkListInfoContext(context.Background())
+}
+
+// GetNetworkListInfoContext is GetNetworkListInfo with a context for cancellation and deadlines
+func (d *Device) GetNetworkListInfoContext(ctx context.Context) (*NLS, error) {
+	msg, err := d.SetGetOneContext(ctx, "NLS", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NLS), nil
+	v, ok := msg.Parsed.(*NLS)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 // hangs
 func (d *Device) GetNetworkInfo() (string, error) {
-	msg, err := d.SetGetOne("NLA", "L000100000000FF") // doesn't hang, but returns junk
+	return d.GetNetworkInfoContext(context.Background())
+}
+
+// GetNetworkInfoContext is GetNetworkInfo with a context for cancellation and deadlines
+func (d *Device) GetNetworkInfoContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "NLA", "L000100000000FF") // doesn't hang, but returns junk
 	if err != nil {
 		return "", err
 	}
@@ -269,62 +503,125 @@ func (d *Device) GetNetworkInfo() (string, error) {
 }
 
 func (d *Device) GetFirmwareVersion() (string, error) {
-	msg, err := d.SetGetOne("FWV", "QSTN")
+	return d.GetFirmwareVersionContext(context.Background())
+}
+
+// GetFirmwareVersionContext is GetFirmwareVersion with a context for cancellation and deadlines
+func (d *Device) GetFirmwareVersionContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "FWV", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetTempData() (uint8, error) {
-	msg, err := d.SetGetOne("TPD", "QSTN")
+	return d.GetTempDataContext(context.Background())
+}
+
+// GetTempDataContext is GetTempData with a context for cancellation and deadlines
+func (d *Device) GetTempDataContext(ctx context.Context) (uint8, error) {
+	msg, err := d.SetGetOneContext(ctx, "TPD", "QSTN")
 	if err != nil {
 		return 0, err
 	}
-	return msg.Parsed.(uint8), err
+	v, ok := msg.Parsed.(uint8)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
 }
 
 // AM/FM tuner preset
 func (d *Device) GetPreset() (string, error) {
-	msg, err := d.SetGetOne("PRS", "QSTN")
+	return d.GetPresetContext(context.Background())
+}
+
+// GetPresetContext is GetPreset with a context for cancellation and deadlines
+func (d *Device) GetPresetContext(ctx context.Context) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "PRS", "QSTN")
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 // AM/FM tuner preset
 func (d *Device) SetPreset(p string) (string, error) {
-	msg, err := d.SetGetOne("PRS", p)
+	return d.SetPresetContext(context.Background(), p)
+}
+
+// SetPresetContext is SetPreset with a context for cancellation and deadlines
+func (d *Device) SetPresetContext(ctx context.Context, p string) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "PRS", p)
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) SetNetworkPreset(p string) (string, error) {
-	// msg, err := d.SetGetOne("NPZ", p)
-	msg, err := d.SetGetOne("NPR", p)
+	return d.SetNetworkPresetContext(context.Background(), p)
+}
+
+// SetNetworkPresetContext is SetNetworkPreset with a context for cancellation and deadlines
+func (d *Device) SetNetworkPresetContext(ctx context.Context, p string) (string, error) {
+	// msg, err := d.SetGetOneContext(ctx, "NPZ", p)
+	msg, err := d.SetGetOneContext(ctx, "NPR", p)
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), nil
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkStatus() (*NetworkStatus, error) {
-	msg, err := d.SetGetOne("NDS", "QSTN")
+	return d.GetNetworkStatusContext(context.Background())
+}
+
+// GetNetworkStatusContext is GetNetworkStatus with a context for cancellation and deadlines
+func (d *Device) GetNetworkStatusContext(ctx context.Context) (*NetworkStatus, error) {
+	msg, err := d.SetGetOneContext(ctx, "NDS", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NetworkStatus), nil
+	v, ok := msg.Parsed.(*NetworkStatus)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) GetNetworkPlayStatus() (*NetworkPlayStatus, error) {
-	msg, err := d.SetGetOne("NST", "QSTN")
+	return d.GetNetworkPlayStatusContext(context.Background())
+}
+
+// GetNetworkPlayStatusContext is GetNetworkPlayStatus with a context for cancellation and deadlines
+func (d *Device) GetNetworkPlayStatusContext(ctx context.Context) (*NetworkPlayStatus, error) {
+	msg, err := d.SetGetOneContext(ctx, "NST", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NetworkPlayStatus), nil
+	v, ok := msg.Parsed.(*NetworkPlayStatus)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
 
 // prs : e.g. Sxx or Pxx
@@ -332,32 +629,65 @@ func (d *Device) GetNetworkPlayStatus() (*NetworkPlayStatus, error) {
 // r Repeat Status: "-": Off, "R": All, "F": Folder, "1": Repeat 1, "x": disable
 // s Shuffle Status: "-": Off, "S": All , "A": Album, "F": Folder, "x": disable
 func (d *Device) SetNetworkPlayStatus(s string) (string, error) {
-	msg, err := d.SetGetOne("NST", s)
+	return d.SetNetworkPlayStatusContext(context.Background(), s)
+}
+
+// SetNetworkPlayStatusContext is SetNetworkPlayStatus with a context for cancellation and deadlines
+func (d *Device) SetNetworkPlayStatusContext(ctx context.Context, s string) (string, error) {
+	msg, err := d.SetGetOneContext(ctx, "NST", s)
 	if err != nil {
 		return "", err
 	}
-	return msg.Parsed.(string), err
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
 }
 
 func (d *Device) SetNetworkServiceTuneIn() error {
-	return d.SetNetworkService(NetSrcTuneIn + "0")
+	return d.SetNetworkServiceTuneInContext(context.Background())
+}
+
+// SetNetworkServiceTuneInContext is SetNetworkServiceTuneIn with a context for cancellation and deadlines
+func (d *Device) SetNetworkServiceTuneInContext(ctx context.Context) error {
+	return d.SetNetworkServiceContext(ctx, NetSrcTuneIn+"0")
 }
 
 func (d *Device) SetNetworkService(s string) error {
-	err := d.SetOnly("NSV", s) // NSV hangs on reads
+	return d.SetNetworkServiceContext(context.Background(), s)
+}
+
+// SetNetworkServiceContext is SetNetworkService with a context for cancellation and deadlines
+func (d *Device) SetNetworkServiceContext(ctx context.Context, s string) error {
+	err := d.SetOnlyContext(ctx, "NSV", s) // NSV hangs on reads
 	return err
 }
 
 func (d *Device) SelectNetworkListItem(i int) error {
+	return d.SelectNetworkListItemContext(context.Background(), i)
+}
+
+// SelectNetworkListItemContext is SelectNetworkListItem with a context for cancellation and deadlines
+func (d *Device) SelectNetworkListItemContext(ctx context.Context, i int) error {
 	line := fmt.Sprintf("I%05d", i)
-	err := d.SetOnly("NLS", line)
+	err := d.SetOnlyContext(ctx, "NLS", line)
 	return err
 }
 
 func (d *Device) GetNetworkMenuStatus() (*NetworkMenuStatus, error) {
-	msg, err := d.SetGetOne("NMS", "QSTN")
+	return d.GetNetworkMenuStatusContext(context.Background())
+}
+
+// GetNetworkMenuStatusContext is GetNetworkMenuStatus with a context for cancellation and deadlines
+func (d *Device) GetNetworkMenuStatusContext(ctx context.Context) (*NetworkMenuStatus, error) {
+	msg, err := d.SetGetOneContext(ctx, "NMS", "QSTN")
 	if err != nil {
 		return nil, err
 	}
-	return msg.Parsed.(*NetworkMenuStatus), nil
+	v, ok := msg.Parsed.(*NetworkMenuStatus)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
 }
@@ -0,0 +1,138 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConnectionState of a persistent Device
+type ConnectionState int
+
+// Connection states
+const (
+	Disconnected ConnectionState = iota
+	Connecting
+	Connected
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	default:
+		return "unknown"
+	}
+}
+
+// reconnect backoff, doubling from min to max
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// errCannotRedial is returned by dialers which only have a single connection to hand out
+var errCannotRedial = fmt.Errorf("%w: connection closed, cannot be re-established", ErrUnsupported)
+
+// OnConnectionStateChange registers fn to be called when a persistent Device connects, loses its
+// connection, or starts reconnecting. fn is called from the listener goroutine and must not block.
+func (d *Device) OnConnectionStateChange(fn func(ConnectionState)) {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+	d.stateHandler = fn
+}
+
+// ConnectionState reports whether the device is currently connected
+func (d *Device) ConnectionState() ConnectionState {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+	if !d.persistent && d.conn != nil {
+		return Connected
+	}
+	return d.state
+}
+
+func (d *Device) setConnectionState(s ConnectionState) {
+	d.connMu.Lock()
+	changed := d.state != s
+	d.state = s
+	fn := d.stateHandler
+	d.connMu.Unlock()
+
+	if changed && fn != nil {
+		fn(s)
+	}
+}
+
+func (d *Device) isClosed() bool {
+	select {
+	case <-d.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// reconnect keeps trying to connect, backing off exponentially, until it works or the Device is closed.
+// Commands are rejected with ErrNotConnected while it runs. Returns false if the listener should stop.
+func (d *Device) reconnect() bool {
+	d.setConnectionState(Disconnected)
+	backoff := minBackoff
+	for {
+		select {
+		case <-d.closed:
+			return false
+		case <-time.After(backoff):
+		}
+
+		d.setConnectionState(Connecting)
+		err := d.Connect()
+		if err == nil {
+			if d.isClosed() {
+				d.disconnect()
+				d.setConnectionState(Disconnected)
+				return false
+			}
+			d.setConnectionState(Connected)
+			// the listener has to be reading while the queries are written, or a synchronous pipe deadlocks
+			go d.requery()
+			return true
+		}
+		d.setConnectionState(Disconnected)
+		if errors.Is(err, errCannotRedial) {
+			return false
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// requery asks for the core state so State and listeners catch up on anything missed while down.
+// Used on (re)connect. Each query holds its command code like any other request, so a reply to it
+// cannot be taken for the reply to a request made at the same time.
+func (d *Device) requery() {
+	ctx, cancel := context.WithTimeout(context.Background(), persistentTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, code := range refreshCommands {
+		wg.Add(1)
+		go func(code string) {
+			defer wg.Done()
+			_, err := d.setGetPersistent(ctx, code, "QSTN")
+			if err != nil && !errors.Is(err, ErrTimeout) {
+				ologger.Printf("requery %s: %s\n", code, err.Error())
+			}
+		}(code)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+package eiscp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxDataSize caps the size of a single frame; the largest thing a receiver sends is the NRI XML
+const maxDataSize = 1 << 20
+
+// maxHeaderSize caps the header size, receivers all send 16 and anything much larger is garbage
+const maxHeaderSize = 256
+
+// Decoder reads eISCP frames from a stream and returns them one message at a time.
+// The header's dataSize field is used to read exactly one frame, so messages which
+// are coalesced into one TCP segment, or split across several, are handled correctly.
+type Decoder struct {
+	r      *bufio.Reader
+	serial bool
+}
+
+// NewDecoder returns a Decoder reading eISCP (network) frames from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: bufio.NewReaderSize(r, 4096),
+	}
+}
+
+// NewISCPDecoder returns a Decoder reading bare ISCP (serial) messages from r
+func NewISCPDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:      bufio.NewReaderSize(r, 4096),
+		serial: true,
+	}
+}
+
+// Decode blocks until a complete frame has been read, then parses it.
+// A frame which cannot be parsed is returned along with a *ProtocolError; the stream is still
+// positioned at the next frame, so it is safe to keep reading. Any other error is from the reader.
+func (dec *Decoder) Decode() (*Message, error) {
+	if dec.serial {
+		return dec.decodeISCP()
+	}
+
+	if err := dec.sync(); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(dec.r, header); err != nil {
+		return nil, err
+	}
+
+	headerSize := binary.BigEndian.Uint32(header[4:8])
+	if headerSize < 16 || headerSize > maxHeaderSize {
+		return nil, &ProtocolError{Frame: header, Reason: fmt.Sprintf("invalid header size: %d", headerSize)}
+	}
+	// skip anything a future protocol version might add to the header
+	if _, err := dec.r.Discard(int(headerSize - 16)); err != nil {
+		return nil, err
+	}
+
+	dataSize := binary.BigEndian.Uint32(header[8:12])
+	if dataSize > maxDataSize {
+		return nil, &ProtocolError{Frame: header, Reason: fmt.Sprintf("frame too large: %d bytes", dataSize)}
+	}
+
+	// the extra header bytes have been skipped, so describe the frame as having a plain 16 byte header
+	frame := make([]byte, 16+dataSize)
+	copy(frame, header)
+	binary.BigEndian.PutUint32(frame[4:8], 16)
+	if _, err := io.ReadFull(dec.r, frame[16:]); err != nil {
+		return nil, err
+	}
+
+	var msg Message
+	if err := msg.Parse(&frame); err != nil {
+		if !msg.Valid {
+			return &msg, err
+		}
+		ologger.Println(err.Error())
+	}
+	return &msg, nil
+}
+
+// sync discards bytes until the stream is positioned at the start of a frame
+func (dec *Decoder) sync() error {
+	for {
+		magic, err := dec.r.Peek(4)
+		if err != nil {
+			return err
+		}
+		if string(magic) == "ISCP" {
+			return nil
+		}
+		if _, err := dec.r.Discard(1); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeISCP reads a serial message, which ends with any of EOF (0x1A), CR or LF
+func (dec *Decoder) decodeISCP() (*Message, error) {
+	var raw []byte
+	for {
+		b, err := dec.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case 0x1A, '\r', '\n':
+			// the terminators come in runs, skip the empty messages between them
+			if len(raw) == 0 {
+				continue
+			}
+			var msg Message
+			if err := msg.ParseISCP(raw); err != nil {
+				if !msg.Valid {
+					return &msg, err
+				}
+				ologger.Println(err.Error())
+			}
+			return &msg, nil
+		default:
+			// drop any line noise before the start of a message
+			if len(raw) == 0 && b != '!' {
+				continue
+			}
+			if len(raw) > maxDataSize {
+				return nil, &ProtocolError{Frame: raw[:64], Reason: fmt.Sprintf("message too large: %d bytes", len(raw))}
+			}
+			raw = append(raw, b)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package eiscp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestDecoderHeaderSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		headerSize uint32
+		bad        bool
+	}{
+		{"standard", 16, false},
+		{"extended", 24, false},
+		{"too short", 8, true},
+		{"huge", 0xFFFFFFF0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte("!1PWR01\x1a\r\n")
+			// a bad header is rejected before any of it past the first 16 bytes is read
+			frame := make([]byte, 16)
+			if !tt.bad {
+				frame = make([]byte, tt.headerSize)
+			}
+			copy(frame, "ISCP")
+			binary.BigEndian.PutUint32(frame[4:8], tt.headerSize)
+			binary.BigEndian.PutUint32(frame[8:12], uint32(len(data)))
+			frame[12] = 0x01
+			frame = append(frame, data...)
+
+			// the decoder has to carry on with the next frame either way
+			stream := append(frame, eiscptest.Frame("MVL", "20")...)
+			dec := NewDecoder(bytes.NewReader(stream))
+
+			msg, err := dec.Decode()
+			var pe *ProtocolError
+			if tt.bad != errors.As(err, &pe) {
+				t.Fatalf("Decode() = %+v, %v", msg, err)
+			}
+			if !tt.bad && (msg.Command != "PWR" || msg.Response != "01") {
+				t.Errorf("Decode() = %s %s, want PWR 01", msg.Command, msg.Response)
+			}
+
+			msg, err = dec.Decode()
+			if err != nil || msg.Command != "MVL" {
+				t.Errorf("next Decode() = %+v, %v", msg, err)
+			}
+		})
+	}
+}
+
+func TestParseISCPTrim(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"!1NTIHello\x1a\r\n", "Hello"},
+		{"!1NTIHello\r", "Hello"},
+		{"!1NTIHello\x1a", "Hello"},
+		// 0x19 is not an end of message byte, it belongs to the value
+		{"!1NTIHello\x19\x1a", "Hello\x19"},
+	}
+
+	for _, tt := range tests {
+		var msg Message
+		if err := msg.ParseISCP([]byte(tt.raw)); err != nil {
+			t.Errorf("ParseISCP(%q): %v", tt.raw, err)
+			continue
+		}
+		if msg.Response != tt.want {
+			t.Errorf("ParseISCP(%q) = %q, want %q", tt.raw, msg.Response, tt.want)
+		}
+	}
+}
@@ -4,10 +4,13 @@ package eiscp
 // https://github.com/miracle2k/onkyo-eiscp/blob/master/eiscp-commands.yaml
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
-	"strings"
+	"os"
 	"sync"
 	"time"
 )
@@ -20,65 +23,267 @@ const (
 	TypeReceiver DeviceType = 0x31
 )
 
+// DialFunc opens a connection to a receiver. It is called again each time the Device reconnects.
+type DialFunc func(ctx context.Context) (net.Conn, error)
+
+// iscpPort is the TCP port receivers listen on for eISCP
+const iscpPort = "60128"
+
 // Device of Onkyo receiver
 type Device struct {
-	conn             *net.TCPConn
-	sender           chan Command
-	privateResponses chan Message
-	Responses        chan Message
-	Host             string
-	mux              sync.Mutex
-	persistent       bool
-	destinationType  DeviceType
-	version          byte
+	conn            io.ReadWriteCloser
+	dec             *Decoder
+	dial            func(ctx context.Context) (io.ReadWriteCloser, error)
+	serial          bool       // serial devices speak bare ISCP rather than eISCP
+	connMu          sync.Mutex // guards conn and dec, which are replaced on reconnect; never held during I/O
+	writeMu         sync.Mutex // keeps whole frames together when several goroutines write
+	state           ConnectionState
+	stateHandler    func(ConnectionState)
+	closed          chan struct{} // closed by Close, stops the reconnect loop
+	pendMu          sync.Mutex
+	pending         map[string]*waiter       // persistent requests waiting for a reply, by command code
+	codeLocks       map[string]chan struct{} // orders persistent requests with the same command code
+	subMu           sync.Mutex
+	subs            map[*subscriber]bool
+	eventSubs       map[*eventSubscriber]bool
+	menu            menuState
+	live            liveState
+	Responses       <-chan Message // every message, for persistent devices; see Subscribe
+	Host            string
+	MAC             string        // set when the device was found by discovery
+	hostMu          sync.Mutex    // guards Host and MAC, which Follow may change
+	mux             chan struct{} // held for the duration of a one-shot request
+	decodeMu        sync.Mutex
+	inflight        chan decoded // a one-shot read which outlived its request, see decode
+	inflightDec     *Decoder
+	persistent      bool
+	destinationType DeviceType
+	version         byte
 }
 
 // just use the NewReceiver shortcut
 func newDevice(host string, deviceType DeviceType, iscpVersion byte, persistent bool) (*Device, error) {
+	var mac string
 	if host == "" {
 		h, err := Discover()
 		if err != nil {
 			return nil, err
 		}
 		host = h
+	} else if !isAddress(host) && (isMAC(host) || !resolves(host)) {
+		// not an address or hostname, see if it is the MAC or friendly name of something on the network
+		if dd, ok := findDevice(host); ok {
+			host = dd.Address()
+			mac = dd.MAC
+		}
 	}
 
 	d := Device{
 		Host:            host,
+		MAC:             mac,
 		destinationType: deviceType,
 		version:         iscpVersion,
 		persistent:      persistent,
 	}
+	d.dial = d.dialHost
 
-	err := d.Connect()
-	if err != nil {
+	if err := d.start(); err != nil {
 		return nil, err
 	}
+	return &d, nil
+}
 
-	if persistent {
-		d.sender = make(chan Command)
-		d.Responses = make(chan Message, 50)        // the channel for the application to listen on
-		d.privateResponses = make(chan Message, 50) // the channel for SetGetAll/SetGet to use
+// NewReceiver - sugar for NewDevice with Receiver as device type and version 1.
+// host may be an IP address (with or without port), a host name, or the MAC address
+// or friendly name of a receiver on the local network. Anything which does not match a receiver
+// is dialed as a host name. If empty, the first receiver found is used.
+func NewReceiver(host string, persistent bool) (*Device, error) {
+	return newDevice(host, TypeReceiver, 0x01, persistent)
+}
 
-		go func(dev Device) {
-			d.persistentListener()
-		}(d)
+// NewReceiverWithDialer uses dial rather than d.Host to open (and re-open) the connection,
+// e.g. to go through a TLS tunnel, a jump host, or to a test server
+func NewReceiverWithDialer(dial DialFunc, persistent bool) (*Device, error) {
+	d := Device{
+		destinationType: TypeReceiver,
+		version:         0x01,
+		persistent:      persistent,
+	}
+	d.dial = func(ctx context.Context) (io.ReadWriteCloser, error) {
+		return dial(ctx)
+	}
 
-		go func(dev Device) {
-			d.persistentSender()
-		}(d)
+	if err := d.start(); err != nil {
+		return nil, err
 	}
+	return &d, nil
+}
 
+// NewReceiverConn uses an already established connection, e.g. one end of a net.Pipe.
+// The connection cannot be re-established, so once it is closed the Device is done.
+func NewReceiverConn(conn net.Conn, persistent bool) (*Device, error) {
+	d := Device{
+		destinationType: TypeReceiver,
+		version:         0x01,
+		persistent:      persistent,
+	}
+	if addr := conn.RemoteAddr(); addr != nil {
+		d.Host = addr.String()
+	}
+	d.dial = dialOnce(conn)
+
+	if err := d.start(); err != nil {
+		return nil, err
+	}
 	return &d, nil
 }
 
-// NewReceiver - sugar for NewDevice with Receiver as device type and version 1
-func NewReceiver(host string, persistent bool) (*Device, error) {
-	return newDevice(host, TypeReceiver, 0x01, persistent)
+// NewSerialReceiver speaks ISCP to a receiver over an RS-232 port (or a pty, or a pipe).
+// The port must already be configured (9600 8N1 on most models).
+// A serial device cannot be re-opened, so once closed the Device is done.
+func NewSerialReceiver(port io.ReadWriteCloser, persistent bool) (*Device, error) {
+	d := Device{
+		Host:            "serial",
+		dial:            dialOnce(port),
+		serial:          true,
+		destinationType: TypeReceiver,
+		version:         0x01,
+		persistent:      persistent,
+	}
+
+	if err := d.start(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// OpenSerialReceiver opens a serial device path, e.g. /dev/ttyUSB0, and uses it for NewSerialReceiver
+func OpenSerialReceiver(path string, persistent bool) (*Device, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewSerialReceiver(f, persistent)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// dialOnce hands out conn the first time it is called, and fails after that
+func dialOnce(conn io.ReadWriteCloser) func(ctx context.Context) (io.ReadWriteCloser, error) {
+	var once sync.Once
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		var c io.ReadWriteCloser
+		once.Do(func() { c = conn })
+		if c == nil {
+			return nil, errCannotRedial
+		}
+		return c, nil
+	}
 }
 
-// Close connection
+// isAddress reports whether host is an IP address or host:port, which can be dialed without discovery
+func isAddress(host string) bool {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return true
+	}
+	return net.ParseIP(host) != nil
+}
+
+// isMAC reports whether host looks like a MAC address, with or without separators
+func isMAC(host string) bool {
+	mac := normalizeMAC(host)
+	if len(mac) != 12 {
+		return false
+	}
+	_, err := hex.DecodeString(mac)
+	return err == nil
+}
+
+// resolves reports whether host is a name DNS knows, so it can be dialed without discovery
+func resolves(host string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	return err == nil && len(addrs) > 0
+}
+
+// dialHost is the default dialer, it connects to d.Host on the eISCP port unless Host includes a port
+func (d *Device) dialHost(ctx context.Context) (io.ReadWriteCloser, error) {
+	addr := d.host()
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, iscpPort)
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// host is d.Host, which may be changed by Follow while the device is in use
+func (d *Device) host() string {
+	d.hostMu.Lock()
+	defer d.hostMu.Unlock()
+	return d.Host
+}
+
+func (d *Device) setHost(host string) {
+	d.hostMu.Lock()
+	defer d.hostMu.Unlock()
+	d.Host = host
+}
+
+func (d *Device) mac() string {
+	d.hostMu.Lock()
+	defer d.hostMu.Unlock()
+	return d.MAC
+}
+
+func (d *Device) setMAC(mac string) {
+	d.hostMu.Lock()
+	defer d.hostMu.Unlock()
+	d.MAC = mac
+}
+
+// start connects and, for persistent devices, starts the listener
+func (d *Device) start() error {
+	d.mux = make(chan struct{}, 1)
+	d.closed = make(chan struct{})
+
+	err := d.Connect()
+	if err != nil {
+		return err
+	}
+
+	if d.persistent {
+		// the channel for the application to listen on, kept for compatibility with code written before Subscribe
+		d.Responses, _ = d.Subscribe(nil)
+
+		go d.persistentListener()
+		go d.requery()
+	}
+	return nil
+}
+
+// Close the connection. Persistent devices stop reconnecting.
 func (d *Device) Close() error {
+	select {
+	case <-d.closed:
+	default:
+		close(d.closed)
+	}
+	err := d.disconnect()
+	// a persistent listener also does this as it stops, one-shot devices have no listener
+	d.closeSubscriptions()
+	return err
+}
+
+// disconnect closes the current connection, without stopping persistent devices from reconnecting
+func (d *Device) disconnect() error {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
 	if d.conn != nil {
 		// d.conn.SetLinger(0)
 		err := d.conn.Close()
@@ -86,71 +291,116 @@ func (d *Device) Close() error {
 			ologger.Println(err.Error())
 		}
 		d.conn = nil
+		d.dec = nil
 		return err
 	}
 	return nil
 }
 
-// Connect to an eISCP device by v4 IP address (not host name)
+// Connect (or reconnect) to the device using its dialer
 func (d *Device) Connect() error {
+	return d.ConnectContext(context.Background())
+}
+
+// ConnectContext is Connect with a context for cancellation and deadlines
+func (d *Device) ConnectContext(ctx context.Context) error {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+
 	if d.conn != nil {
 		ologger.Println("already connected")
 		return nil
 	}
 
-	// now that I can move data, switch this back to net.Dial to be more flexible
-	r := net.TCPAddr{
-		IP:   net.ParseIP(d.Host),
-		Port: 60128,
-	}
-
-	conn, err := net.DialTCP("tcp", nil, &r)
-	d.conn = conn
+	conn, err := d.dial(ctx)
 	if err != nil {
 		ologger.Println(err.Error())
 		return err
 	}
+	d.conn = conn
+	if d.serial {
+		d.dec = NewISCPDecoder(conn)
+	} else {
+		d.dec = NewDecoder(conn)
+	}
 	return nil
 }
 
+// connection returns the current connection and its decoder, nil when not connected
+func (d *Device) connection() (io.ReadWriteCloser, *Decoder) {
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+	return d.conn, d.dec
+}
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// isTimeout reports whether err is a read deadline expiring
+func isTimeout(err error) bool {
+	var te interface{ Timeout() bool }
+	return errors.As(err, &te) && te.Timeout()
+}
+
 // read is used for non-persistent connections (e.g. onkyo cli tool)
-func (d *Device) read(command string) (*MultiMessage, error) {
-	if d.conn == nil {
-		return nil, fmt.Errorf("not connected")
+func (d *Device) read(ctx context.Context, command string) (*MultiMessage, error) {
+	conn, dec := d.connection()
+	if conn == nil {
+		return nil, ErrNotConnected
 	}
 
-	mm := MultiMessage{}
-	blocksize := 1024 // should read interface for MTU
-	bufsize := 100 * blocksize
-	for {
-		raw := make([]byte, 0, bufsize)
-		tmp := make([]byte, blocksize)
-		for {
-			d.conn.SetReadDeadline(time.Now().Add(time.Second * 10))
-			defer d.conn.SetDeadline(time.Time{})
-
-			n, err := d.conn.Read(tmp)
-			if err != nil && err != io.EOF && !strings.Contains(err.Error(), "i/o timeout") {
-				ologger.Printf("cannot read data from device: %s", err.Error())
-				return nil, err
-			} else if err != nil && strings.Contains(err.Error(), "i/o timeout") {
-				return &mm, nil
-			}
-			raw = append(raw, tmp[:n]...)
-			if err == io.EOF || n != blocksize {
-				break
+	// TCP connections and most serial ports support deadlines, an io.Pipe or a pty opened as an *os.File may not
+	dl, deadlines := conn.(readDeadliner)
+	if deadlines {
+		deadline, _ := ctx.Deadline()
+		deadlines = dl.SetReadDeadline(deadline) == nil
+	}
+	if deadlines {
+		// wake the read up if the context is cancelled before the deadline
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				dl.SetReadDeadline(time.Now())
+			case <-done:
 			}
+		}()
+		// wait for the watcher before clearing the deadline, so it cannot cut short the next read
+		defer func() {
+			close(done)
+			<-stopped
+			dl.SetReadDeadline(time.Time{})
+		}()
+	}
 
-			// let NRI and others catch up
-			time.Sleep(time.Millisecond * 10)
+	mm := MultiMessage{}
+	for {
+		msg, err := d.decode(ctx, dec, deadlines)
+		if err != nil && ctx.Err() == context.Canceled {
+			return nil, ctx.Err()
 		}
-		var msg Message
-		msg.Parse(&raw)
-		if !msg.Valid {
-			return &mm, nil
+		if (err != nil && ctx.Err() != nil) || isTimeout(err) {
+			// deadline passed, return what was heard in the meantime
+			return &mm, fmt.Errorf("%w: no response to %s", ErrTimeout, command)
+		}
+		var pe *ProtocolError
+		if errors.As(err, &pe) {
+			// the decoder has skipped the bad frame, keep listening for the reply
+			ologger.Println(err.Error())
+			continue
+		}
+		if err != nil {
+			// the receiver closed the connection or it broke, the next request dials again
+			ologger.Printf("cannot read data from device: %s", err.Error())
+			d.disconnect()
+			return nil, fmt.Errorf("%w: %v", ErrNotConnected, err)
 		}
 		// ologger.Printf("got message [%s]: [%s]\n", msg.Command, msg.Response)
-		mm.Messages = append(mm.Messages, &msg)
+		d.dispatch(msg)
+		mm.Messages = append(mm.Messages, msg)
 		if msg.Command == command {
 			// ologger.Println("got original command, returning")
 			return &mm, nil
@@ -158,89 +408,122 @@ func (d *Device) read(command string) (*MultiMessage, error) {
 	}
 }
 
-// long-lived clients should use the persistentListner and read from the d.Responses channel
-func (d *Device) persistentListener() error {
-	if d.conn == nil {
-		return fmt.Errorf("not connected")
+// decoded is the result of a read started by decode
+type decoded struct {
+	msg *Message
+	err error
+}
+
+// decode reads the next message from dec. Connections with working read deadlines are woken up by read itself;
+// for anything else (io.Pipe, some serial drivers) the read runs in the background so ctx can still end
+// the wait. A read still running when ctx ends is kept, and its message goes to the next call, since
+// closing the connection instead would lose a serial port for good.
+func (d *Device) decode(ctx context.Context, dec *Decoder, deadlines bool) (*Message, error) {
+	if deadlines {
+		return dec.Decode()
 	}
 
-	blocksize := 1024 // get interface MTU
-	block := make([]byte, blocksize)
-	bufsize := 100 * blocksize
-	buf := make([]byte, bufsize)
-	var bytesread int
-	var msg Message
+	d.decodeMu.Lock()
+	if d.inflight == nil || d.inflightDec != dec {
+		ch := make(chan decoded, 1)
+		go func() {
+			msg, err := dec.Decode()
+			ch <- decoded{msg: msg, err: err}
+		}()
+		d.inflight = ch
+		d.inflightDec = dec
+	}
+	ch := d.inflight
+	d.decodeMu.Unlock()
+
+	select {
+	case r := <-ch:
+		d.decodeMu.Lock()
+		if d.inflight == ch {
+			d.inflight = nil
+		}
+		d.decodeMu.Unlock()
+		return r.msg, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// long-lived clients should use the persistentListner and read from the d.Responses channel.
+// If the connection drops it reconnects with backoff, until Close is called.
+func (d *Device) persistentListener() {
+	defer d.closeSubscriptions()
+
+	d.setConnectionState(Connected)
+	_, dec := d.connection()
 	for {
-		n, err := d.conn.Read(block)
-		if err != nil {
-			ologger.Printf("persistentListener error: [%s], resetting\n", err.Error())
-			if err := d.Close(); err != nil {
-				ologger.Println(err.Error())
-				return err
-			}
-			if err := d.Connect(); err != nil {
-				ologger.Println(err.Error())
-				return err
+		if dec == nil {
+			if !d.reconnect() {
+				return
 			}
+			_, dec = d.connection()
 			continue
 		}
 
-		// copy the read block into the buffer
-		for pos, b := range block[:n] {
-			buf[bytesread+pos] = b
+		msg, err := dec.Decode()
+		var pe *ProtocolError
+		if errors.As(err, &pe) {
+			// the decoder has skipped the bad frame, the connection is still good
+			ologger.Println(err.Error())
+			continue
 		}
-
-		// if it wasn't a full block, it must be complete, parse and send to channel
-		if n != blocksize {
-			bytesread = 0
-			msg.Parse(&buf)
-			if !msg.Valid {
-				ologger.Printf("invalid message: %+v\n", msg)
+		if err != nil {
+			if d.isClosed() {
+				d.failWaiters()
+				d.setConnectionState(Disconnected)
+				return
 			}
-			d.Responses <- msg
-			d.privateResponses <- msg
+			ologger.Printf("persistentListener error: [%s], resetting\n", err.Error())
+			d.disconnect()
+			d.failWaiters()
+			dec = nil
 			continue
 		}
-		// otherwise keep reading
-		bytesread += n
-		if bytesread == bufsize {
-			ologger.Printf("buffer full, %n:\n%s\n", bytesread, buf)
-			bytesread = 0 // trash everything read so far
-			continue      // the remainder of this read will be garbage
-		}
-		// NRI needs this on TX-NR686 (lowest threshold not researched)
-		time.Sleep(time.Millisecond * 10)
-	}
-	return nil
-}
 
-func (d *Device) persistentSender() error {
-	if d.conn == nil {
-		return fmt.Errorf("not connected")
+		d.dispatch(msg)
 	}
+}
 
-	for cmd := range d.sender {
-		d.send(cmd)
-	}
-	return nil
+// dispatch updates the device's view of the receiver, then hands msg to whoever is waiting for it
+func (d *Device) dispatch(msg *Message) {
+	d.menu.update(msg)
+	events := d.live.update(msg)
+	d.route(msg)
+	d.publish(msg)
+	d.publishEvents(events)
 }
 
-func (d *Device) writeCommand(command, arg string) error {
+func (d *Device) writeCommand(ctx context.Context, command, arg string) error {
 	c := Command{
 		Code:  command,
 		Value: arg,
 	}
-	if d.persistent {
-		d.sender <- c
-		return nil
+	if !d.persistent {
+		// one-shot connections reconnect on demand, persistent ones wait for the listener to do it
+		if conn, _ := d.connection(); conn == nil {
+			if err := d.ConnectContext(ctx); err != nil {
+				return err
+			}
+		}
 	}
-	return d.send(c)
+	err := d.send(c)
+	if err != nil && !d.persistent && !errors.Is(err, ErrNotConnected) {
+		// drop the broken connection so the next request dials again
+		d.disconnect()
+		return fmt.Errorf("%w: %v", ErrNotConnected, err)
+	}
+	return err
 }
 
 func (d *Device) send(c Command) error {
-	if d.conn == nil {
-		// be smart and try to reconnect if possible
-		return fmt.Errorf("not connected")
+	conn, _ := d.connection()
+	if conn == nil {
+		return ErrNotConnected
 	}
 
 	msg := Message{
@@ -249,65 +532,119 @@ func (d *Device) send(c Command) error {
 		raw:         []byte(c.Code + c.Value),
 	}
 	m := msg.BuildEISCP()
+	if d.serial {
+		m = msg.BuildISCP()
+	}
 	// ologger.Printf("m: %+v %s\n", m, string(m))
-	_, err := d.conn.Write(m)
+	// a write can block until the receiver reads, so it must not hold connMu, which the reader needs
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	_, err := conn.Write(m)
 
 	return err
 }
 
+// default timeouts, used when the context passed in has no deadline
+const (
+	readTimeout       = 10 * time.Second // one-shot connections
+	persistentTimeout = 3 * time.Second
+)
+
+// withDefaultTimeout applies the default timeout for the connection type unless ctx already has a deadline
+func (d *Device) withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	if d.persistent {
+		return context.WithTimeout(ctx, persistentTimeout)
+	}
+	return context.WithTimeout(ctx, readTimeout)
+}
+
+// lock serializes one-shot requests, giving up if ctx is done while waiting
+func (d *Device) lock(ctx context.Context) error {
+	select {
+	case d.mux <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Device) unlock() {
+	<-d.mux
+}
+
 // SetOnly sends a command and does not check for a response
 func (d *Device) SetOnly(command, arg string) error {
-	d.mux.Lock()
-	defer d.mux.Unlock()
+	return d.SetOnlyContext(context.Background(), command, arg)
+}
+
+// SetOnlyContext sends a command and does not check for a response
+func (d *Device) SetOnlyContext(ctx context.Context, command, arg string) error {
+	if d.persistent {
+		return d.writeCommand(ctx, command, arg)
+	}
+
+	ctx, cancel := d.withDefaultTimeout(ctx)
+	defer cancel()
 
-	err := d.writeCommand(command, arg)
+	if err := d.lock(ctx); err != nil {
+		return err
+	}
+	defer d.unlock()
+
+	err := d.writeCommand(ctx, command, arg)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// Set sends a command and returns all responses
+// SetGetAll sends a command and returns all responses
 func (d *Device) SetGetAll(command, arg string) (*MultiMessage, error) {
-	d.mux.Lock()
-	defer d.mux.Unlock()
+	return d.SetGetAllContext(context.Background(), command, arg)
+}
 
-	err := d.writeCommand(command, arg)
-	if err != nil {
-		return nil, err
-	}
+// SetGetAllContext sends a command and returns all responses received until the one for the command.
+// On persistent connections only the reply to the command is returned, messages for other codes go
+// to whoever is waiting for them. It gives up when ctx is done; if ctx has no deadline the default timeout applies.
+// A timeout returns ErrTimeout along with anything received before it.
+func (d *Device) SetGetAllContext(ctx context.Context, command, arg string) (*MultiMessage, error) {
+	ctx, cancel := d.withDefaultTimeout(ctx)
+	defer cancel()
 
+	// persistent connections can have many requests in flight, one per command code
 	if d.persistent {
-		var pmm MultiMessage
-		for {
-			select {
-			case msg := <-d.privateResponses:
-				// ologger.Printf("SetGetAll: %+v\n", msg)
-				pmm.Messages = append(pmm.Messages, &msg)
-				if msg.Command == command {
-					return &pmm, nil
-				}
-			case <-time.After(time.Second * 3):
-				return &pmm, fmt.Errorf("timeout reached: %d non-responses received", len(pmm.Messages))
-			}
-		}
+		return d.setGetPersistent(ctx, command, arg)
 	}
 
-	mm, err := d.read(command)
-	if err != nil {
+	if err := d.lock(ctx); err != nil {
 		return nil, err
 	}
-	return mm, nil
+	defer d.unlock()
+
+	if err := d.writeCommand(ctx, command, arg); err != nil {
+		return nil, err
+	}
+	return d.read(ctx, command)
 }
 
+// SetGetOne sends a command and returns the response to it
 func (d *Device) SetGetOne(command, arg string) (*Message, error) {
+	return d.SetGetOneContext(context.Background(), command, arg)
+}
+
+// SetGetOneContext sends a command and returns the response to it, giving up when ctx is done
+func (d *Device) SetGetOneContext(ctx context.Context, command, arg string) (*Message, error) {
 	// SetGetAll does the requred locking
-	mm, err := d.SetGetAll(command, arg)
+	mm, err := d.SetGetAllContext(ctx, command, arg)
 	if err != nil {
 		return nil, err
 	}
-	if len(mm.Messages) == 0 {
-		return nil, fmt.Errorf("no response")
+	msg := mm.Messages[len(mm.Messages)-1]
+	if msg.Response == "N/A" {
+		return nil, ErrNotAvailable
 	}
-	return mm.Messages[len(mm.Messages)-1], nil
+	return msg, nil
 }
@@ -0,0 +1,330 @@
+package eiscp_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	eiscp "github.com/cloudkucooland/go-onkyo"
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestMain(m *testing.M) {
+	// the malformed frames and fuzz seeds are logged, which is only noise here
+	eiscp.SetLogger(log.New(ioutil.Discard, "", 0))
+	os.Exit(m.Run())
+}
+
+// receiver starts a fake receiver and a Device connected to it, both closed when the test ends
+func receiver(t *testing.T, persistent bool) (*eiscptest.Server, *eiscp.Device) {
+	t.Helper()
+	s := eiscptest.NewServer()
+	d, err := eiscp.NewReceiverWithDialer(s.Dial, persistent)
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+		s.Close()
+	})
+	return s, d
+}
+
+// malformed frames the decoder has to skip: a header which is too short, and data which is not a message
+func malformed() []byte {
+	short := eiscptest.Frame("MVL", "99")
+	binary.BigEndian.PutUint32(short[4:8], 8)
+
+	junk := eiscptest.Frame("MVL", "99")
+	copy(junk[16:], "??")
+	return append(short, junk...)
+}
+
+func TestQuery(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		s, d := receiver(t, persistent)
+
+		power, err := d.GetPower()
+		if err != nil || !power {
+			t.Errorf("persistent %v: GetPower() = %v, %v", persistent, power, err)
+		}
+		vol, err := d.SetVolume(0x2A)
+		if err != nil || vol != 0x2A {
+			t.Errorf("persistent %v: SetVolume() = %v, %v", persistent, vol, err)
+		}
+		if v, _ := s.Get("MVL"); v != "2A" {
+			t.Errorf("persistent %v: server volume is %q, want 2A", persistent, v)
+		}
+		vol, err = d.GetVolume()
+		if err != nil || vol != 0x2A {
+			t.Errorf("persistent %v: GetVolume() = %v, %v", persistent, vol, err)
+		}
+
+		s.Remove("PWR")
+		if _, err := d.GetPower(); !errors.Is(err, eiscp.ErrNotAvailable) {
+			t.Errorf("persistent %v: GetPower() error = %v, want ErrNotAvailable", persistent, err)
+		}
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	s, d := receiver(t, true)
+
+	msgs, cancel := d.Subscribe(eiscp.Commands("MVL"))
+	defer cancel()
+	events, cancelEvents := d.SubscribeEvents()
+	defer cancelEvents()
+
+	// make sure the listener is up before changing anything
+	if _, err := d.GetPower(); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("MVL", "33")
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if msg.Command != "MVL" {
+				t.Errorf("filtered subscription got %s", msg.Command)
+			}
+			continue
+		case ev := <-events:
+			if ev != (eiscp.VolumeChanged{Level: 0x33}) {
+				continue
+			}
+		case <-timeout:
+			t.Fatal("no VolumeChanged event")
+		}
+		break
+	}
+	if vol := d.State().Volume; vol != 0x33 {
+		t.Errorf("State().Volume = %d, want %d", vol, 0x33)
+	}
+}
+
+func TestMalformedFrames(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		s, d := receiver(t, persistent)
+		s.Script("MVL", eiscptest.Raw(malformed()), eiscptest.Reply("MVL", "30"))
+
+		vol, err := d.GetVolume()
+		if err != nil || vol != 0x30 {
+			t.Errorf("persistent %v: GetVolume() = %v, %v", persistent, vol, err)
+		}
+		// the connection is still usable
+		if _, err := d.GetPower(); err != nil {
+			t.Errorf("persistent %v: GetPower() after malformed frames: %v", persistent, err)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		s, d := receiver(t, persistent)
+		s.Script("MVL", eiscptest.Delay(300*time.Millisecond), eiscptest.Reply("MVL", "30"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		start := time.Now()
+		_, err := d.GetVolumeContext(ctx)
+		cancel()
+		if !errors.Is(err, eiscp.ErrTimeout) {
+			t.Errorf("persistent %v: GetVolume() error = %v, want ErrTimeout", persistent, err)
+		}
+		if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+			t.Errorf("persistent %v: timed out after %v", persistent, elapsed)
+		}
+
+		// the late reply must not be mistaken for the next one
+		if power, err := d.GetPower(); err != nil || !power {
+			t.Errorf("persistent %v: GetPower() after timeout = %v, %v", persistent, power, err)
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	s, d := receiver(t, true)
+
+	states := make(chan eiscp.ConnectionState, 16)
+	d.OnConnectionStateChange(func(cs eiscp.ConnectionState) { states <- cs })
+	if _, err := d.GetPower(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.DisconnectAll()
+	// the listener may still be reporting its first connection
+	want := []eiscp.ConnectionState{eiscp.Disconnected, eiscp.Connecting, eiscp.Connected}
+	for len(want) > 0 {
+		select {
+		case got := <-states:
+			if got == want[0] {
+				want = want[1:]
+			} else if len(want) < 3 {
+				t.Fatalf("connection state %v, want %v", got, want[0])
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("still waiting for %v", want[0])
+		}
+	}
+
+	if power, err := d.GetPower(); err != nil || !power {
+		t.Errorf("GetPower() after reconnect = %v, %v", power, err)
+	}
+}
+
+// a net.Pipe has no buffering, so reads and writes on one goroutine, or under one lock, deadlock
+func TestPipe(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		s := eiscptest.NewServer()
+		d, err := eiscp.NewReceiverConn(s.Pipe(), persistent)
+		if err != nil {
+			s.Close()
+			t.Fatal(err)
+		}
+
+		if power, err := d.GetPower(); err != nil || !power {
+			t.Errorf("persistent %v: GetPower() = %v, %v", persistent, power, err)
+		}
+		if vol, err := d.SetVolume(0x2A); err != nil || vol != 0x2A {
+			t.Errorf("persistent %v: SetVolume() = %v, %v", persistent, vol, err)
+		}
+
+		closed := make(chan error)
+		go func() { closed <- d.Close() }()
+		select {
+		case <-closed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("persistent %v: Close() did not return", persistent)
+		}
+		s.Close()
+	}
+}
+
+func TestOneShotReconnect(t *testing.T) {
+	s, d := receiver(t, false)
+	if _, err := d.GetPower(); err != nil {
+		t.Fatal(err)
+	}
+
+	s.DisconnectAll()
+	if _, err := d.GetPower(); !errors.Is(err, eiscp.ErrNotConnected) {
+		t.Errorf("GetPower() after the receiver hung up: %v, want ErrNotConnected", err)
+	}
+	// the next request dials again
+	if power, err := d.GetPower(); err != nil || !power {
+		t.Errorf("GetPower() after reconnecting = %v, %v", power, err)
+	}
+}
+
+func TestSetGetAllTimeout(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		s, d := receiver(t, persistent)
+		s.Script("NLS", eiscptest.Delay(300*time.Millisecond))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		_, err := d.SetGetAllContext(ctx, "NLS", "QSTN")
+		cancel()
+		if !errors.Is(err, eiscp.ErrTimeout) {
+			t.Errorf("persistent %v: SetGetAll() error = %v, want ErrTimeout", persistent, err)
+		}
+	}
+}
+
+// noDeadlines is a connection which has SetReadDeadline but cannot use it, like some *os.File ports
+type noDeadlines struct {
+	net.Conn
+}
+
+func (noDeadlines) SetReadDeadline(time.Time) error {
+	return os.ErrNoDeadline
+}
+
+func TestOneShotWithoutDeadlines(t *testing.T) {
+	s := eiscptest.NewServer()
+	defer s.Close()
+	d, err := eiscp.NewReceiverConn(noDeadlines{s.Pipe()}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	s.Script("MVL", eiscptest.Delay(300*time.Millisecond), eiscptest.Reply("MVL", "30"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := d.GetVolumeContext(ctx); !errors.Is(err, eiscp.ErrTimeout) {
+		t.Errorf("GetVolume() error = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("timed out after %v", elapsed)
+	}
+	if power, err := d.GetPower(); err != nil || !power {
+		t.Errorf("GetPower() after timeout = %v, %v", power, err)
+	}
+}
+
+func TestDialContext(t *testing.T) {
+	s := eiscptest.NewServer()
+	defer s.Close()
+
+	// the first dial works, after that the receiver has gone away and dials hang
+	dialed := false
+	dial := func(ctx context.Context) (net.Conn, error) {
+		if !dialed {
+			dialed = true
+			return s.Dial(ctx)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	d, err := eiscp.NewReceiverWithDialer(dial, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	s.DisconnectAll()
+	d.GetPower()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := d.GetPowerContext(ctx); err == nil {
+		t.Error("GetPower() succeeded without a connection")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("dial ignored the context, gave up after %v", elapsed)
+	}
+}
+
+func TestCloseEndsSubscriptions(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		_, d := receiver(t, persistent)
+		msgs, _ := d.Subscribe(nil)
+		events, _ := d.SubscribeEvents()
+		d.Close()
+
+		timeout := time.After(2 * time.Second)
+		for msgs != nil || events != nil {
+			select {
+			case _, ok := <-msgs:
+				if !ok {
+					msgs = nil
+				}
+			case _, ok := <-events:
+				if !ok {
+					events = nil
+				}
+			case <-timeout:
+				t.Fatalf("persistent %v: subscriptions still open after Close", persistent)
+			}
+		}
+	}
+}
@@ -2,37 +2,361 @@ package eiscp
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/brutella/dnssd"
 )
 
+// BroadcastAddr is where the eISCP discovery query is sent
+const BroadcastAddr = "255.255.255.255:60128"
+
+// DiscoveredDevice describes a receiver found on the network
+type DiscoveredDevice struct {
+	Host   string // IPv4 address
+	Port   int    // ISCP port, normally 60128
+	Name   string // friendly name, only reported via mDNS
+	Model  string // e.g. TX-NR686, only reported via eISCP broadcast
+	Region string // DX: North America, XX: Europe/Asia, JJ: Japan
+	MAC    string // upper-case hex, no separators
+}
+
+// Address is the host to pass to NewReceiver, it only includes the port when it is not the default
+func (dd DiscoveredDevice) Address() string {
+	if dd.Port == 0 || strconv.Itoa(dd.Port) == iscpPort {
+		return dd.Host
+	}
+	return net.JoinHostPort(dd.Host, strconv.Itoa(dd.Port))
+}
+
+// Discover returns the IP address of the first receiver that answers either mDNS or the eISCP broadcast
 func Discover() (string, error) {
 	discovered := ""
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	found := func(e dnssd.BrowseEntry) {
-		if strings.Contains(e.Name, "@Onkyo") {
-			// look through the list of IPs, pick something IPv4, IPV6 doesn't seem to work
-			for _, ipa := range e.IPs {
-				if ipa.To4() != nil {
-					discovered = ipa.String()
-					cancel()
-					return
-				}
-			}
+	var once sync.Once
+	found := func(dd DiscoveredDevice) {
+		if dd.Model == "" && !dd.isOnkyo() {
+			return
+		}
+		once.Do(func() {
+			discovered = dd.Address()
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := discoverECN(ctx, BroadcastAddr, found); err != nil {
+			ologger.Printf("discovery: %v\n", err)
+		}
+	}()
+
+	err := discoverMDNS(ctx, found)
+	wg.Wait()
+	if discovered != "" {
+		return discovered, nil
+	}
+	if err != nil {
+		return discovered, err
+	}
+	return discovered, fmt.Errorf("no receivers found")
+}
+
+// DiscoverAll collects every receiver which answers mDNS or the eISCP broadcast before ctx is done.
+// Devices seen both ways are merged by MAC address. It runs until ctx is done, so give it a timeout.
+func DiscoverAll(ctx context.Context) ([]DiscoveredDevice, error) {
+	var found deviceSet
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := discoverECN(ctx, BroadcastAddr, func(dd DiscoveredDevice) { found.add(dd, false) }); err != nil {
+			ologger.Printf("discovery: %v\n", err)
+		}
+	}()
+	err := discoverMDNS(ctx, func(dd DiscoveredDevice) { found.add(dd, true) })
+	wg.Wait()
+
+	out := found.list()
+	if len(out) == 0 && err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// deviceSet collects discovered devices, merging those seen more than once by MAC address
+type deviceSet struct {
+	mu       sync.Mutex
+	order    []string
+	devices  map[string]*DiscoveredDevice
+	mdnsOnly map[string]bool
+}
+
+// add merges dd into the set; fromMDNS is whether it was found by mDNS rather than the eISCP broadcast
+func (ds *deviceSet) add(dd DiscoveredDevice, fromMDNS bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.devices == nil {
+		ds.devices = make(map[string]*DiscoveredDevice)
+		ds.mdnsOnly = make(map[string]bool)
+	}
+
+	key := dd.MAC
+	if key == "" {
+		key = dd.Host
+	}
+	existing, ok := ds.devices[key]
+	if !ok {
+		ds.devices[key] = &dd
+		ds.order = append(ds.order, key)
+		ds.mdnsOnly[key] = fromMDNS
+		return
+	}
+	existing.merge(dd)
+	if !fromMDNS {
+		ds.mdnsOnly[key] = false
+	}
+}
+
+// list returns the devices in the order they were first seen
+func (ds *deviceSet) list() []DiscoveredDevice {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	var out []DiscoveredDevice
+	for _, key := range ds.order {
+		dd := ds.devices[key]
+		// everything with AirPlay shows up in mDNS, only keep those which are known receivers
+		if ds.mdnsOnly[key] && !dd.isOnkyo() {
+			continue
+		}
+		out = append(out, *dd)
+	}
+	return out
+}
+
+// merge fills in anything dd is missing from other
+func (dd *DiscoveredDevice) merge(other DiscoveredDevice) {
+	if dd.Host == "" || other.Model != "" {
+		// the eISCP reply comes from the address actually listening for ISCP
+		dd.Host = other.Host
+	}
+	if other.Model != "" {
+		dd.Model = other.Model
+		dd.Region = other.Region
+		dd.Port = other.Port
+	}
+	if dd.Name == "" {
+		dd.Name = other.Name
+	}
+	if dd.MAC == "" {
+		dd.MAC = other.MAC
+	}
+}
+
+// isOnkyo is used to pick receivers out of all the AirPlay devices on the network
+func (dd DiscoveredDevice) isOnkyo() bool {
+	return strings.HasPrefix(dd.Name, "Onkyo")
+}
+
+// matches reports whether sel is this device's MAC address, friendly name or model
+func (dd DiscoveredDevice) matches(sel string) bool {
+	if dd.MAC != "" && normalizeMAC(sel) == dd.MAC {
+		return true
+	}
+	return strings.EqualFold(sel, dd.Name) || strings.EqualFold(sel, dd.Model)
+}
+
+// findDevice looks for a receiver with the given MAC address, friendly name or model
+func findDevice(sel string) (DiscoveredDevice, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var match DiscoveredDevice
+	var once sync.Once
+	found := func(dd DiscoveredDevice) {
+		if !dd.matches(sel) {
+			return
 		}
+		once.Do(func() {
+			match = dd
+			cancel()
+		})
 	}
 
-	if err := dnssd.LookupType(ctx, "_raop._tcp.local.", found, reject); err != nil {
-		if err.Error() != "context canceled" {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		discoverECN(ctx, BroadcastAddr, found)
+	}()
+	discoverMDNS(ctx, found)
+	wg.Wait()
+	return match, match.Host != ""
+}
+
+// DiscoverBroadcast sends the eISCP discovery query (!xECNQSTN) to addr, normally BroadcastAddr,
+// and returns every receiver which replies before ctx is done
+func DiscoverBroadcast(ctx context.Context, addr string) ([]DiscoveredDevice, error) {
+	var mu sync.Mutex
+	var devices []DiscoveredDevice
+	seen := make(map[string]bool)
+
+	err := discoverECN(ctx, addr, func(dd DiscoveredDevice) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[dd.MAC] {
+			return
+		}
+		seen[dd.MAC] = true
+		devices = append(devices, dd)
+	})
+	return devices, err
+}
+
+// discoverMDNS browses for AirPlay (raop) services, which are named like MAC@friendly name
+func discoverMDNS(ctx context.Context, found func(DiscoveredDevice)) error {
+	add := func(e dnssd.BrowseEntry) {
+		if dd, ok := parseBrowseEntry(e); ok {
+			found(dd)
+		}
+	}
+
+	if err := dnssd.LookupType(ctx, "_raop._tcp.local.", add, reject); err != nil {
+		if ctx.Err() == nil {
 			ologger.Printf("discovery: %v\n", err)
-			return discovered, err
+			return err
 		}
 	}
-	return discovered, nil
+	return nil
+}
+
+// parseBrowseEntry turns an AirPlay service entry into a DiscoveredDevice
+func parseBrowseEntry(e dnssd.BrowseEntry) (DiscoveredDevice, bool) {
+	dd := DiscoveredDevice{
+		Port: 60128,
+		Name: e.Name,
+	}
+	// AirPlay service names are MAC@friendly name
+	if at := strings.Index(e.Name, "@"); at > 0 {
+		dd.MAC = normalizeMAC(e.Name[:at])
+		dd.Name = e.Name[at+1:]
+	}
+
+	// look through the list of IPs, pick something IPv4, IPV6 doesn't seem to work
+	for _, ipa := range e.IPs {
+		if ipa.To4() != nil {
+			dd.Host = ipa.String()
+			return dd, true
+		}
+	}
+	return dd, false
+}
+
+// discoverECN sends the eISCP discovery query to addr and calls found for every reply until ctx is done
+func discoverECN(ctx context.Context, addr string, found func(DiscoveredDevice)) error {
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// unblock ReadFrom when the context ends
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	query := Message{
+		Destination: 'x', // any device type
+		Version:     0x01,
+		raw:         []byte("ECNQSTN"),
+	}
+	if _, err := conn.WriteTo(query.BuildEISCP(), raddr); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		udp, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		reply := buf[:n]
+		var msg Message
+		if err := msg.Parse(&reply); err != nil {
+			continue
+		}
+		// our own query comes back when broadcasting
+		if msg.Command != "ECN" || msg.Response == "QSTN" {
+			continue
+		}
+
+		dd, err := parseECN(msg.Response)
+		if err != nil {
+			ologger.Printf("discovery: %v\n", err)
+			continue
+		}
+		dd.Host = udp.IP.String()
+		found(dd)
+	}
+}
+
+// parseECN parses an ECN reply, e.g. "TX-NR686/60128/DX/0009B0123456"
+func parseECN(r string) (DiscoveredDevice, error) {
+	var dd DiscoveredDevice
+	parts := strings.Split(strings.TrimRight(r, "\x19\x1a\r\n"), "/")
+	if len(parts) < 4 {
+		return dd, fmt.Errorf("invalid ECN reply: %s", r)
+	}
+
+	port, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return dd, fmt.Errorf("invalid ECN port: %s", parts[1])
+	}
+
+	dd.Model = parts[0]
+	dd.Port = port
+	dd.Region = parts[2]
+	// some models pad the MAC with a device identifier
+	dd.MAC = normalizeMAC(parts[3])
+	if len(dd.MAC) > 12 {
+		dd.MAC = dd.MAC[:12]
+	}
+	return dd, nil
+}
+
+// normalizeMAC upper-cases a MAC address and strips any separators
+func normalizeMAC(mac string) string {
+	mac = strings.ToUpper(mac)
+	return strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac)
 }
 
 func reject(e dnssd.BrowseEntry) {
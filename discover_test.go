@@ -0,0 +1,127 @@
+package eiscp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestParseECN(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  DiscoveredDevice
+		err   bool
+	}{
+		{"TX-NR686/60128/DX/0009B0123456", DiscoveredDevice{Model: "TX-NR686", Port: 60128, Region: "DX", MAC: "0009B0123456"}, false},
+		{"TX-RZ50/60129/XX/0009b0abcdef\x19\r\n", DiscoveredDevice{Model: "TX-RZ50", Port: 60129, Region: "XX", MAC: "0009B0ABCDEF"}, false},
+		{"TX-8270/60128/JJ/00:09:B0:12:34:56", DiscoveredDevice{Model: "TX-8270", Port: 60128, Region: "JJ", MAC: "0009B0123456"}, false},
+		// padded with a device identifier
+		{"TX-NR7100/60128/DX/0009B0123456000000", DiscoveredDevice{Model: "TX-NR7100", Port: 60128, Region: "DX", MAC: "0009B0123456"}, false},
+		{"TX-NR686/60128/DX", DiscoveredDevice{}, true},
+		{"TX-NR686/port/DX/0009B0123456", DiscoveredDevice{}, true},
+		{"", DiscoveredDevice{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseECN(tt.reply)
+		if (err != nil) != tt.err {
+			t.Errorf("parseECN(%q) error = %v", tt.reply, err)
+			continue
+		}
+		if !tt.err && got != tt.want {
+			t.Errorf("parseECN(%q) = %+v, want %+v", tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverBroadcast(t *testing.T) {
+	s := eiscptest.NewServer()
+	defer s.Close()
+	r := s.NewResponder("TX-NR686", "0009B0123456")
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	found, err := DiscoverBroadcast(ctx, r.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("found %+v, want one device", found)
+	}
+
+	want := DiscoveredDevice{Host: "127.0.0.1", Port: r.Port, Model: "TX-NR686", Region: "DX", MAC: "0009B0123456"}
+	if found[0] != want {
+		t.Errorf("found %+v, want %+v", found[0], want)
+	}
+	// the responder reports the server's port, so the address can be dialed
+	if found[0].Address() != s.Addr() {
+		t.Errorf("Address() = %s, want %s", found[0].Address(), s.Addr())
+	}
+}
+
+func TestDiscoverBroadcastDuplicates(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// a receiver on two interfaces, or hearing the query twice, replies more than once
+	go func() {
+		buf := make([]byte, 1024)
+		_, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		conn.WriteTo(eiscptest.Frame("ECN", "TX-NR686/60128/DX/0009B0123456"), from)
+		conn.WriteTo(eiscptest.Frame("ECN", "TX-NR686/60128/DX/0009b0123456"), from)
+		conn.WriteTo(eiscptest.Frame("ECN", "TX-RZ50/60128/DX/0009B0ABCDEF"), from)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	found, err := DiscoverBroadcast(ctx, conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].MAC != "0009B0123456" || found[1].MAC != "0009B0ABCDEF" {
+		t.Errorf("found %+v, want one of each MAC", found)
+	}
+}
+
+func TestDeviceSet(t *testing.T) {
+	var ds deviceSet
+	// seen both ways, the eISCP reply comes from the wired address
+	ds.add(DiscoveredDevice{Host: "192.168.1.11", Port: 60128, Name: "Onkyo TX-NR686", MAC: "0009B0123456"}, true)
+	ds.add(DiscoveredDevice{Host: "192.168.1.10", Port: 60128, Model: "TX-NR686", Region: "DX", MAC: "0009B0123456"}, false)
+	// broadcast first, and an AirPlay name which does not say Onkyo
+	ds.add(DiscoveredDevice{Host: "192.168.1.20", Port: 60129, Model: "TX-RZ50", Region: "XX", MAC: "0009B0ABCDEF"}, false)
+	ds.add(DiscoveredDevice{Host: "192.168.1.20", Port: 60128, Name: "Living Room", MAC: "0009B0ABCDEF"}, true)
+	// AirPlay speakers and TVs which are not receivers
+	ds.add(DiscoveredDevice{Host: "192.168.1.30", Port: 60128, Name: "Kitchen", MAC: "A4B1C1000000"}, true)
+	// a receiver only found by mDNS, e.g. broadcasts are blocked
+	ds.add(DiscoveredDevice{Host: "192.168.1.40", Port: 60128, Name: "Onkyo TX-8270", MAC: "0009B0000040"}, true)
+	// no MAC in the reply, keyed by host
+	ds.add(DiscoveredDevice{Host: "192.168.1.50", Port: 60128, Model: "TX-NR555"}, false)
+	ds.add(DiscoveredDevice{Host: "192.168.1.50", Port: 60128, Model: "TX-NR555"}, false)
+
+	want := []DiscoveredDevice{
+		{Host: "192.168.1.10", Port: 60128, Name: "Onkyo TX-NR686", Model: "TX-NR686", Region: "DX", MAC: "0009B0123456"},
+		{Host: "192.168.1.20", Port: 60129, Name: "Living Room", Model: "TX-RZ50", Region: "XX", MAC: "0009B0ABCDEF"},
+		{Host: "192.168.1.40", Port: 60128, Name: "Onkyo TX-8270", MAC: "0009B0000040"},
+		{Host: "192.168.1.50", Port: 60128, Model: "TX-NR555"},
+	}
+	got := ds.list()
+	if len(got) != len(want) {
+		t.Fatalf("got %d devices, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("device %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package eiscptest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Responder answers eISCP discovery queries (!xECNQSTN) on a local UDP port
+type Responder struct {
+	conn  net.PacketConn
+	Model string
+	Port  int
+	MAC   string
+}
+
+// NewResponder starts answering discovery queries for s; pass Addr() to eiscp.DiscoverBroadcast
+func (s *Server) NewResponder(model, mac string) *Responder {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("eiscptest: failed to listen: %v", err))
+	}
+
+	r := Responder{
+		conn:  conn,
+		Model: model,
+		Port:  s.ln.Addr().(*net.TCPAddr).Port,
+		MAC:   mac,
+	}
+	go r.serve()
+	return &r
+}
+
+// Addr is the host:port the responder is listening on
+func (r *Responder) Addr() string {
+	return r.conn.LocalAddr().String()
+}
+
+// Close stops the responder
+func (r *Responder) Close() {
+	r.conn.Close()
+}
+
+func (r *Responder) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		cmd, err := readCommand(bufio.NewReader(bytes.NewReader(buf[:n])))
+		if err != nil || !strings.HasPrefix(cmd, "ECNQSTN") {
+			continue
+		}
+		reply := Frame("ECN", fmt.Sprintf("%s/%d/DX/%s", r.Model, r.Port, r.MAC))
+		r.conn.WriteTo(reply, from)
+	}
+}
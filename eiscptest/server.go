@@ -0,0 +1,445 @@
+// Package eiscptest provides a fake Onkyo receiver for testing code which uses eiscp.Device.
+//
+// The server speaks eISCP on a local TCP port, keeps the state a receiver would (power, volume,
+// mute, source, listening mode, zones), answers QSTN queries and sends unsolicited status messages
+// to every connected client when the state changes, the same way a real receiver does.
+// Individual commands can be scripted to reply with arbitrary messages, delays or malformed frames.
+package eiscptest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Server is a fake receiver listening on a random local port
+type Server struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	state    map[string]string
+	scripts  map[string][]Step
+	clients  map[net.Conn]bool
+	received []string
+	wg       sync.WaitGroup
+}
+
+// Step is one action taken in response to a scripted command
+type Step struct {
+	code  string
+	value string
+	raw   []byte
+	delay time.Duration
+}
+
+// Reply sends a well-formed message, e.g. Reply("NLT", "...")
+func Reply(code, value string) Step {
+	return Step{code: code, value: value}
+}
+
+// Raw sends b to the client as-is, use it for malformed or partial frames
+func Raw(b []byte) Step {
+	return Step{raw: b}
+}
+
+// Delay pauses before the next step
+func Delay(d time.Duration) Step {
+	return Step{delay: d}
+}
+
+// DefaultState is the state a new Server starts with: powered on, network source, stereo
+var DefaultState = map[string]string{
+	"PWR": "01",
+	"MVL": "20",
+	"AMT": "00",
+	"SLI": "2B",
+	"LMD": "00",
+	"PRS": "01",
+	"TUN": "10110",
+	"IFA": "HDMI 1,Dolby TrueHD,48 kHz,5.1ch,Dolby Atmos,7.1.2ch,",
+	"SCH": "002",
+	"SCN": "SiriusXM Hits 1",
+	"SAT": "Artist",
+	"STI": "Title",
+	"DPS": "01",
+	"DSN": "BBC Radio 4",
+	"UDD": "MNBBC National DAB",
+	"SWL": "00",
+	"CTL": "00",
+	"CLV": "000000000000000000000000",
+	"TFR": "B00T00",
+	"TCT": "B00T00",
+	"TSR": "B00T00",
+	"ZPW": "00",
+	"ZVL": "20",
+	"ZMT": "00",
+	"SLZ": "2B",
+	"TUZ": "10110",
+	"PRZ": "01",
+	"ZTN": "B00T00",
+	"ZBL": "00",
+	"PW3": "00",
+	"VL3": "20",
+	"MT3": "00",
+	"SL3": "2B",
+	"TU3": "10110",
+	"PR3": "01",
+	"TN3": "B00T00",
+	"BL3": "00",
+	"PW4": "00",
+	"VL4": "20",
+	"MT4": "00",
+	"SL4": "2B",
+	"TU4": "10110",
+	"PR4": "01",
+	"NRI": `<?xml version="1.0" encoding="utf-8"?><response status="ok"><device id="TX-NR686"><brand>ONKYO</brand><category>AV Receiver</category><model>TX-NR686</model><macaddress>0009B0000000</macaddress><friendlyname>eiscptest</friendlyname><zonelist count="4"><zone id="1" value="1" name="Main" volmax="80" volstep="0"/><zone id="2" value="1" name="Zone2" volmax="80" volstep="0"/><zone id="3" value="1" name="Zone3" volmax="60" volstep="0"/><zone id="4" value="0" name="Zone4" volmax="0" volstep="0"/></zonelist><controllist><control id="Bass" value="1" zone="1" min="-10" max="10" step="2"/><control id="Treble" value="1" zone="1" min="-10" max="10" step="2"/></controllist><tuners count="2"><tuner band="FM" min="87.5" max="107.9" step="0.2"/><tuner band="AM" min="530" max="1710" step="10"/></tuners><presetlist count="40"><preset id="01" band="1" freq="101.10" name="KUOW"/><preset id="02" band="2" freq="810" name="KGO"/><preset id="03" band="0" freq="0" name=""/></presetlist></device></response>`,
+}
+
+// volumes are the commands which accept UP/DOWN and keep their value as two hex digits
+var volumes = map[string]bool{
+	"MVL": true,
+	"ZVL": true,
+	"VL3": true,
+	"VL4": true,
+}
+
+// channels are the commands which accept UP/DOWN and keep a channel or preset number, with its format
+var channels = map[string]string{
+	"XCH": "%03d",
+	"SCH": "%03d",
+	"DPS": "%02X",
+}
+
+// levels are the commands which accept UP/DOWN and keep their value in half dB steps, e.g. "+06"
+var levels = map[string]bool{
+	"SWL": true,
+	"SW2": true,
+	"CTL": true,
+}
+
+// tones are the commands which set bass or treble on their own, e.g. "B+2", but reply with both, e.g. "B+2T00"
+var tones = map[string]bool{
+	"ZTN": true,
+	"TN3": true,
+	"TFR": true,
+	"TFW": true,
+	"TCT": true,
+	"TSR": true,
+	"TSB": true,
+	"THT": true,
+}
+
+// NewServer starts a fake receiver on a random port on the loopback interface.
+// It panics if it cannot listen, tests cannot do anything useful in that case.
+func NewServer() *Server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("eiscptest: failed to listen: %v", err))
+	}
+
+	s := Server{
+		ln:      ln,
+		state:   make(map[string]string),
+		scripts: make(map[string][]Step),
+		clients: make(map[net.Conn]bool),
+	}
+	for k, v := range DefaultState {
+		s.state[k] = v
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return &s
+}
+
+// Addr is the host:port the server is listening on, it can be used as the Device host
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Dial connects to the server, it can be passed to eiscp.NewReceiverWithDialer
+func (s *Server) Dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", s.Addr())
+}
+
+// Pipe connects to the server over a net.Pipe rather than TCP, it can be passed to eiscp.NewReceiverConn.
+// Writes on a pipe wait for the other end to read, so it catches code which cannot read and write at the same time.
+func (s *Server) Pipe() net.Conn {
+	client, server := net.Pipe()
+	s.mu.Lock()
+	s.clients[server] = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.handle(server)
+	return client
+}
+
+// Close stops listening, disconnects all clients and waits for them to finish
+func (s *Server) Close() {
+	s.ln.Close()
+	s.mu.Lock()
+	for c := range s.clients {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// DisconnectAll drops every client connection but keeps listening, to exercise reconnects
+func (s *Server) DisconnectAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		c.Close()
+	}
+}
+
+// Get returns the current value for a command code
+func (s *Server) Get(code string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.state[code]
+	return v, ok
+}
+
+// Set changes the value for a command code and notifies all clients, as if changed from the front panel
+func (s *Server) Set(code, value string) {
+	s.mu.Lock()
+	s.state[code] = value
+	s.mu.Unlock()
+	s.Send(code, value)
+}
+
+// Remove forgets a command code, queries for it are answered with N/A
+func (s *Server) Remove(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.state, code)
+}
+
+// SetNRI replaces the XML returned for NRI queries
+func (s *Server) SetNRI(xml string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state["NRI"] = xml
+}
+
+// Script replaces the default handling for a command code: each time it is received, the steps are played in order.
+// Scripts run alongside other commands, so a Delay only holds up its own replies, like a slow query on a real receiver.
+func (s *Server) Script(code string, steps ...Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(steps) == 0 {
+		delete(s.scripts, code)
+		return
+	}
+	s.scripts[code] = steps
+}
+
+// Received returns every command received so far, e.g. "MVLQSTN"
+func (s *Server) Received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.received))
+	copy(out, s.received)
+	return out
+}
+
+// Send sends an unsolicited message to all clients without changing any state
+func (s *Server) Send(code, value string) {
+	s.SendRaw(Frame(code, value))
+}
+
+// SendRaw sends b to all clients as-is
+func (s *Server) SendRaw(b []byte) {
+	// a pipe client blocks the write until it reads, which must not hold up the rest of the server
+	s.mu.Lock()
+	clients := make([]net.Conn, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		c.Write(b)
+	}
+}
+
+// Frame builds an eISCP frame the way a receiver sends it, terminated with EOF CR LF
+func Frame(code, value string) []byte {
+	data := []byte("!1" + code + value + "\x1a\r\n")
+	buf := bytes.Buffer{}
+	buf.WriteString("ISCP")
+	binary.Write(&buf, binary.BigEndian, uint32(16))
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.Write([]byte{0x01, 0, 0, 0})
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.clients[c] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	for {
+		cmd, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if len(cmd) < 3 {
+			continue
+		}
+		s.command(cmd[:3], cmd[3:])
+	}
+}
+
+// command does what a receiver would do with a command from a client
+func (s *Server) command(code, value string) {
+	s.mu.Lock()
+	s.received = append(s.received, code+value)
+	steps, scripted := s.scripts[code]
+	current, known := s.state[code]
+	s.mu.Unlock()
+
+	if scripted {
+		s.wg.Add(1)
+		go s.play(steps)
+		return
+	}
+
+	if value == "QSTN" {
+		if !known {
+			s.Send(code, "N/A")
+			return
+		}
+		s.Send(code, current)
+		return
+	}
+
+	if volumes[code] && (value == "UP" || value == "DOWN") {
+		vol, _ := strconv.ParseUint(current, 16, 8)
+		if value == "UP" && vol < 0x64 {
+			vol++
+		}
+		if value == "DOWN" && vol > 0 {
+			vol--
+		}
+		value = fmt.Sprintf("%02X", vol)
+	}
+	if code == "TUN" && (value == "UP" || value == "DOWN") {
+		// FM in 200 kHz steps, AM in 10 kHz steps
+		freq, _ := strconv.Atoi(current)
+		step := 20
+		if freq < 2000 {
+			step = 10
+		}
+		if value == "DOWN" {
+			step = -step
+		}
+		value = fmt.Sprintf("%05d", freq+step)
+	}
+	if format, ok := channels[code]; ok && (value == "UP" || value == "DOWN") {
+		base := 10
+		if format == "%02X" {
+			base = 16
+		}
+		ch, _ := strconv.ParseInt(current, base, 16)
+		if value == "UP" {
+			ch++
+		} else if ch > 1 {
+			ch--
+		}
+		value = fmt.Sprintf(format, ch)
+	}
+	if levels[code] && (value == "UP" || value == "DOWN") {
+		level, _ := strconv.ParseInt(current, 16, 8)
+		if value == "UP" && level < 0x18 {
+			level++
+		}
+		if value == "DOWN" && level > -0x1E {
+			level--
+		}
+		switch {
+		case level > 0:
+			value = fmt.Sprintf("+%02X", level)
+		case level < 0:
+			value = fmt.Sprintf("-%02X", -level)
+		default:
+			value = "00"
+		}
+	}
+	if tones[code] && len(value) == 3 && len(current) == 6 {
+		switch value[0] {
+		case 'B':
+			value = value + current[3:]
+		case 'T':
+			value = current[:3] + value
+		}
+	}
+	s.Set(code, value)
+}
+
+func (s *Server) play(steps []Step) {
+	defer s.wg.Done()
+	for _, step := range steps {
+		switch {
+		case step.delay > 0:
+			time.Sleep(step.delay)
+		case step.raw != nil:
+			s.SendRaw(step.raw)
+		default:
+			s.Send(step.code, step.value)
+		}
+	}
+}
+
+// readCommand reads one eISCP frame from a client and returns the command and value, e.g. "PWR01"
+func readCommand(r *bufio.Reader) (string, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	if string(header[:4]) != "ISCP" {
+		return "", fmt.Errorf("eiscptest: bad frame header: %q", header)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(header[8:12]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+	data = bytes.TrimRight(data, "\x1a\r\n")
+	if len(data) < 2 || data[0] != '!' {
+		return "", fmt.Errorf("eiscptest: bad message: %q", data)
+	}
+	return string(data[2:]), nil
+}
@@ -0,0 +1,183 @@
+package eiscptest
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// client is a raw connection to the server, speaking eISCP by hand
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, s *Server) *client {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *client) send(code, value string) {
+	c.t.Helper()
+	if _, err := c.conn.Write(Frame(code, value)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) expect(want string) {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	got, err := readCommand(c.r)
+	if err != nil {
+		c.t.Fatalf("waiting for %s: %v", want, err)
+	}
+	if got != want {
+		c.t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := dial(t, s)
+
+	c.send("PWR", "QSTN")
+	c.expect("PWR01")
+	c.send("MVL", "QSTN")
+	c.expect("MVL20")
+
+	s.Remove("PWR")
+	c.send("PWR", "QSTN")
+	c.expect("PWRN/A")
+	c.send("XYZ", "QSTN")
+	c.expect("XYZN/A")
+}
+
+func TestSetNotifiesEveryClient(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	a := dial(t, s)
+	b := dial(t, s)
+
+	// both clients must be registered before the change is sent
+	a.send("PWR", "QSTN")
+	a.expect("PWR01")
+	b.send("PWR", "QSTN")
+	b.expect("PWR01")
+
+	s.Set("MVL", "2A")
+	a.expect("MVL2A")
+	b.expect("MVL2A")
+
+	// a command from one client is seen by the other, as on a real receiver
+	a.send("AMT", "01")
+	a.expect("AMT01")
+	b.expect("AMT01")
+	if v, _ := s.Get("AMT"); v != "01" {
+		t.Errorf("AMT is %q, want 01", v)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := dial(t, s)
+
+	tests := []struct {
+		code, value, want string
+	}{
+		{"MVL", "UP", "MVL21"},
+		{"MVL", "DOWN", "MVL20"},
+		{"TUN", "UP", "TUN10130"},
+		{"SWL", "DOWN", "SWL-01"},
+		{"SWL", "UP", "SWL00"},
+		{"DPS", "UP", "DPS02"},
+		{"TFR", "B+2", "TFRB+2T00"},
+		{"TFR", "T-4", "TFRB+2T-4"},
+	}
+	for _, tt := range tests {
+		c.send(tt.code, tt.value)
+		c.expect(tt.want)
+	}
+}
+
+func TestScript(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := dial(t, s)
+
+	s.Script("NLT",
+		Raw([]byte("ISCP\x00\x00")),
+		Delay(50*time.Millisecond),
+		Raw(Frame("NLS", "C0P")),
+		Reply("NLT", "done"),
+	)
+
+	start := time.Now()
+	c.send("NLT", "QSTN")
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	// the partial header runs into the next frame, so check the bytes rather than the frames
+	partial := make([]byte, 6)
+	if _, err := io.ReadFull(c.r, partial); err != nil || string(partial) != "ISCP\x00\x00" {
+		t.Fatalf("got %q, %v", partial, err)
+	}
+	c.expect("NLSC0P")
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("delay not applied, replied after %v", elapsed)
+	}
+	c.expect("NLTdone")
+
+	// clearing the script restores the default handling
+	s.Script("NLT")
+	c.send("NLT", "QSTN")
+	c.expect("NLTN/A")
+}
+
+func TestReceived(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := dial(t, s)
+
+	c.send("PWR", "QSTN")
+	c.expect("PWR01")
+	c.send("MVL", "UP")
+	c.expect("MVL21")
+
+	got := s.Received()
+	want := []string{"PWRQSTN", "MVLUP"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestDisconnectAll(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := dial(t, s)
+	c.send("PWR", "QSTN")
+	c.expect("PWR01")
+
+	s.DisconnectAll()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := readCommand(c.r); err == nil {
+		t.Fatal("connection still open")
+	}
+
+	// still listening
+	c = dial(t, s)
+	c.send("PWR", "QSTN")
+	c.expect("PWR01")
+}
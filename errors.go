@@ -0,0 +1,32 @@
+package eiscp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by Device calls, use errors.Is to check for them
+var (
+	ErrNotConnected = errors.New("not connected")
+	ErrTimeout      = errors.New("timeout waiting for response")
+	ErrNotAvailable = errors.New("not available") // the receiver answered N/A
+	ErrUnsupported  = errors.New("not supported")
+)
+
+// ProtocolError is returned when the receiver sends something which cannot be understood
+type ProtocolError struct {
+	Frame  []byte // the raw frame, or as much of it as was read
+	Reason string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("protocol error: %s: %q", e.Reason, e.Frame)
+}
+
+// unexpected is the error for a reply which could not be parsed into the type the caller wanted
+func unexpected(msg *Message) error {
+	return &ProtocolError{
+		Frame:  []byte(msg.Command + msg.Response),
+		Reason: "unexpected response",
+	}
+}
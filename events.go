@@ -0,0 +1,146 @@
+package eiscp
+
+import (
+	"sync"
+)
+
+// Event is a change to the receiver's state, one of the *Changed types below.
+// Events are only sent when a value actually changes.
+type Event interface {
+	isEvent()
+}
+
+// PowerChanged is sent when the main zone is turned on or off
+type PowerChanged struct{ On bool }
+
+// VolumeChanged is sent when the main zone volume changes
+type VolumeChanged struct{ Level uint8 }
+
+// MuteChanged is sent when the main zone is muted or unmuted
+type MuteChanged struct{ Muted bool }
+
+// SourceChanged is sent when the main zone input changes, use SourceToName for a readable name
+type SourceChanged struct{ Source Source }
+
+// ListeningModeChanged is sent when the listening mode changes
+type ListeningModeChanged struct{ Mode string }
+
+// TunerPresetChanged is sent when the AM/FM tuner preset changes
+type TunerPresetChanged struct{ Preset string }
+
+// TemperatureChanged is sent when the receiver reports a new temperature
+type TemperatureChanged struct{ Celsius uint8 }
+
+// NetworkPlayStatusChanged is sent when network playback starts, stops, or changes repeat/shuffle
+type NetworkPlayStatusChanged struct{ Status NetworkPlayStatus }
+
+// NowPlayingChanged is sent when the network track title, artist or album changes
+type NowPlayingChanged struct{ NowPlaying NowPlaying }
+
+// RDSChanged is sent when the FM station's name, radio text or program type changes, see Tuner.Status
+type RDSChanged struct {
+	PSName      string
+	RadioText   string
+	ProgramType string
+}
+
+// ZoneChanged is sent when anything about zones 2-4 changes
+type ZoneChanged struct {
+	Zone  int
+	State ZoneState
+}
+
+func (PowerChanged) isEvent()             {}
+func (VolumeChanged) isEvent()            {}
+func (MuteChanged) isEvent()              {}
+func (SourceChanged) isEvent()            {}
+func (ListeningModeChanged) isEvent()     {}
+func (TunerPresetChanged) isEvent()       {}
+func (TemperatureChanged) isEvent()       {}
+func (NetworkPlayStatusChanged) isEvent() {}
+func (NowPlayingChanged) isEvent()        {}
+func (RDSChanged) isEvent()               {}
+func (ZoneChanged) isEvent()              {}
+
+type eventSubscriber struct {
+	ch     chan Event
+	mu     sync.Mutex
+	closed bool
+}
+
+// SubscribeEvents returns a channel of typed change events, and a function to cancel the subscription.
+// Like Subscribe, a slow reader loses its oldest events rather than holding up the connection.
+// The channel is closed when the subscription is cancelled or the Device is closed.
+func (d *Device) SubscribeEvents() (<-chan Event, func()) {
+	s := &eventSubscriber{
+		ch: make(chan Event, defaultBuffer),
+	}
+
+	d.subMu.Lock()
+	if d.eventSubs == nil {
+		d.eventSubs = make(map[*eventSubscriber]bool)
+	}
+	d.eventSubs[s] = true
+	d.subMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			d.subMu.Lock()
+			delete(d.eventSubs, s)
+			d.subMu.Unlock()
+			s.close()
+		})
+	}
+	return s.ch, cancel
+}
+
+// publishEvents hands events to every event subscriber
+func (d *Device) publishEvents(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	d.subMu.Lock()
+	subs := make([]*eventSubscriber, 0, len(d.eventSubs))
+	for s := range d.eventSubs {
+		subs = append(subs, s)
+	}
+	d.subMu.Unlock()
+
+	for _, s := range subs {
+		for _, ev := range events {
+			s.deliver(ev)
+		}
+	}
+}
+
+func (s *eventSubscriber) deliver(ev Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	for {
+		select {
+		case s.ch <- ev:
+			return
+		default:
+		}
+		// full, discard the oldest and try again
+		select {
+		case <-s.ch:
+		default:
+		}
+	}
+}
+
+func (s *eventSubscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -22,37 +22,55 @@ type MultiMessage struct {
 	Messages []*Message
 }
 
-// Parse raw message from network into an eISCP message
-func (msg *Message) Parse(rawP *[]byte) {
+// Parse raw message from network into an eISCP message.
+// A malformed frame returns a *ProtocolError and leaves Valid false. If the frame is fine but the
+// value cannot be parsed, Valid is true, Parsed is nil, and the error says why.
+func (msg *Message) Parse(rawP *[]byte) error {
+	msg.Valid = false
 	raw := *rawP
-	if string(raw[:4]) != "ISCP" {
-		// return ologger.Errorf("this is not an EISCP message: %s", string(*rawP))
-		msg.Valid = false
-		return
+	if len(raw) < 16 || string(raw[:4]) != "ISCP" {
+		return &ProtocolError{Frame: raw, Reason: "not an eISCP message"}
 	}
 	msg.headerSize = binary.BigEndian.Uint32(raw[4:8])
 	if msg.headerSize != 16 {
-		// return ologger.Errorf("invalid header size")
-		msg.Valid = false
-		return
+		return &ProtocolError{Frame: raw, Reason: "invalid header size"}
 	}
 
 	msg.dataSize = binary.BigEndian.Uint32(raw[8:12])
 	msg.Version = raw[12]
 	if msg.Version != 1 {
-		msg.Valid = false
-		return
+		return &ProtocolError{Frame: raw, Reason: "unsupported version"}
 	}
+	if uint64(len(raw)) < uint64(msg.headerSize)+uint64(msg.dataSize) {
+		return &ProtocolError{Frame: raw, Reason: "truncated frame"}
+	}
+
+	return msg.ParseISCP(raw[msg.headerSize : msg.headerSize+msg.dataSize])
+}
 
-	msg.Command = string(raw[18:21])
-	msg.Response = string(raw[21 : 16+msg.dataSize-3])
+// ParseISCP parses a bare ISCP message as sent over the serial port, e.g. "!1PWR01".
+// Any combination of the end-of-message characters (EOF, CR, LF) may follow it.
+func (msg *Message) ParseISCP(raw []byte) error {
+	msg.Valid = false
+	raw = bytes.TrimRight(raw, "\x1a\r\n")
+	if len(raw) < 5 || raw[0] != '!' {
+		return &ProtocolError{Frame: raw, Reason: "not an ISCP message"}
+	}
+
+	msg.Destination = raw[1]
+	msg.Command = string(raw[2:5])
+	msg.Response = string(raw[5:])
 	msg.Valid = true
+	if msg.Response == "N/A" {
+		// the model doesn't support the command, or not in its current state
+		return nil
+	}
 	p, err := msg.parseResponseValue()
 	if err != nil {
-		ologger.Println(err.Error())
-		return
+		return err
 	}
 	msg.Parsed = p
+	return nil
 }
 
 // BuildISCP - Build ISCP message
@@ -0,0 +1,111 @@
+//go:build go1.18
+// +build go1.18
+
+package eiscp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+// fuzzSeeds cover every command whose value is parsed by fixed offsets, in full and cut short
+var fuzzSeeds = []struct {
+	code  string
+	value string
+}{
+	{"NLT", "0122000000050100FF0000Internet Radio"},
+	{"NLT", "0122"},
+	{"NLS", "A0-My Favorites"},
+	{"NLS", "C"},
+	{"TPD", "F100C 38"},
+	{"TPD", "F10"},
+	{"NDS", "EM-"},
+	{"NDS", "E"},
+	{"NST", "P--"},
+	{"NST", "P"},
+	{"NMS", "MxxxxS20e"},
+	{"NMS", "Mxx"},
+	{"TUN", "10110"},
+	{"TUN", "00810"},
+	{"TUN", "-"},
+	{"CLV", "000000+05-0A000000000000"},
+	{"CLV", "+0"},
+	{"ZTN", "B+2T-4"},
+	{"TN3", "B00T00"},
+	{"TFR", "B-AT+A"},
+	{"TCT", "B+"},
+	{"ZBL", "-A"},
+	{"ZBL", "+"},
+	{"SWL", "+06"},
+	{"SWL", "-F"},
+	{"SWL", "+"},
+	{"CTL", "00"},
+	{"IFA", "HDMI 1,Dolby TrueHD,48 kHz,5.1ch,Dolby Atmos,7.1.2ch,"},
+	{"IFA", ","},
+	{"UDD", "MNBBC National DAB"},
+	{"MVL", "ZZ"},
+	{"PWR", "N/A"},
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(eiscptest.Frame(s.code, s.value))
+	}
+	f.Add([]byte("ISCP"))
+	f.Add([]byte("ISCP\x00\x00\x00\x10\xff\xff\xff\xff\x01\x00\x00\x00!1PWR01"))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		var msg Message
+		if err := msg.Parse(&raw); err == nil && !msg.Valid {
+			t.Errorf("no error but not valid: %q", raw)
+		}
+	})
+}
+
+func FuzzParseISCP(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte("!1" + s.code + s.value + "\x1a\r\n"))
+	}
+	f.Add([]byte("!1"))
+	f.Add([]byte("!"))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		var msg Message
+		if err := msg.ParseISCP(raw); err == nil && !msg.Valid {
+			t.Errorf("no error but not valid: %q", raw)
+		}
+	})
+}
+
+func FuzzDecoder(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(eiscptest.Frame(s.code, s.value), false)
+		f.Add([]byte("!1"+s.code+s.value+"\x1a\r\n"), true)
+	}
+	// two frames in one read, and noise before a frame
+	f.Add(append(eiscptest.Frame("PWR", "01"), eiscptest.Frame("MVL", "20")...), false)
+	f.Add(append([]byte("garbage"), eiscptest.Frame("AMT", "00")...), false)
+	f.Add([]byte("noise!1PWR01\r!1MVL20\x1a"), true)
+
+	f.Fuzz(func(t *testing.T, stream []byte, serial bool) {
+		dec := NewDecoder(bytes.NewReader(stream))
+		if serial {
+			dec = NewISCPDecoder(bytes.NewReader(stream))
+		}
+		// every Decode consumes input, so the stream must run out
+		for i := 0; i <= len(stream); i++ {
+			msg, err := dec.Decode()
+			if err == nil && msg == nil {
+				t.Fatal("nil message without an error")
+			}
+			if err != nil {
+				if _, ok := err.(*ProtocolError); !ok {
+					return
+				}
+			}
+		}
+		t.Fatalf("decoder did not reach the end of %d bytes", len(stream))
+	})
+}
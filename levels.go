@@ -0,0 +1,294 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LevelControl is a temporary speaker level adjustment, named by its command code
+type LevelControl string
+
+// Level controls
+const (
+	LevelSubwoofer  LevelControl = "SWL"
+	LevelSubwoofer2 LevelControl = "SW2" // second subwoofer, on dual-sub models
+	LevelCenter     LevelControl = "CTL"
+)
+
+// levelRanges are the limits of each level control in dB
+var levelRanges = map[LevelControl][2]float64{
+	LevelSubwoofer:  {-15, 12},
+	LevelSubwoofer2: {-15, 12},
+	LevelCenter:     {-12, 12},
+}
+
+// Channel is a speaker, as named in CLV
+type Channel string
+
+// Channels in the order CLV reports them, not every model has all of them
+var Channels = []Channel{"FL", "FR", "C", "SL", "SR", "SBL", "SBR", "SW", "FHL", "FHR", "FWL", "FWR"}
+
+// ChannelLevels are the temporary levels of each channel in dB
+type ChannelLevels map[Channel]float64
+
+// channelLevelRange is the limit either side of 0 dB for CLV
+const channelLevelRange = 12
+
+// GetLevel gets a level in dB
+func (d *Device) GetLevel(lc LevelControl) (float64, error) {
+	return d.GetLevelContext(context.Background(), lc)
+}
+
+// GetLevelContext is GetLevel with a context for cancellation and deadlines
+func (d *Device) GetLevelContext(ctx context.Context, lc LevelControl) (float64, error) {
+	return d.setLevel(ctx, lc, "QSTN")
+}
+
+// SetLevel sets a level in dB. Older models only take whole dB, newer ones half dB steps;
+// the step is learned from the receiver's replies, asking it first if need be.
+func (d *Device) SetLevel(lc LevelControl, db float64) (float64, error) {
+	return d.SetLevelContext(context.Background(), lc, db)
+}
+
+// SetLevelContext is SetLevel with a context for cancellation and deadlines
+func (d *Device) SetLevelContext(ctx context.Context, lc LevelControl, db float64) (float64, error) {
+	r, ok := levelRanges[lc]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", lc, ErrUnsupported)
+	}
+	if db < r[0] || db > r[1] {
+		return 0, fmt.Errorf("%s %.1f dB out of range %.0f to %.0f", lc, db, r[0], r[1])
+	}
+
+	half, err := d.levelHalfSteps(ctx, lc)
+	if err != nil {
+		return 0, err
+	}
+	return d.setLevel(ctx, lc, formatDB(db, half))
+}
+
+// LevelUp raises a level by one step
+func (d *Device) LevelUp(lc LevelControl) (float64, error) {
+	return d.LevelUpContext(context.Background(), lc)
+}
+
+// LevelUpContext is LevelUp with a context for cancellation and deadlines
+func (d *Device) LevelUpContext(ctx context.Context, lc LevelControl) (float64, error) {
+	return d.setLevel(ctx, lc, "UP")
+}
+
+// LevelDown lowers a level by one step
+func (d *Device) LevelDown(lc LevelControl) (float64, error) {
+	return d.LevelDownContext(context.Background(), lc)
+}
+
+// LevelDownContext is LevelDown with a context for cancellation and deadlines
+func (d *Device) LevelDownContext(ctx context.Context, lc LevelControl) (float64, error) {
+	return d.setLevel(ctx, lc, "DOWN")
+}
+
+func (d *Device) setLevel(ctx context.Context, lc LevelControl, value string) (float64, error) {
+	msg, err := d.SetGetOneContext(ctx, string(lc), value)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := msg.Parsed.(float64)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
+}
+
+// levelHalfSteps reports whether the receiver uses half dB steps for lc
+func (d *Device) levelHalfSteps(ctx context.Context, lc LevelControl) (bool, error) {
+	if half, ok := d.live.halfSteps(string(lc)); ok {
+		return half, nil
+	}
+	if _, err := d.GetLevelContext(ctx, lc); err != nil {
+		return false, err
+	}
+	if half, ok := d.live.halfSteps(string(lc)); ok {
+		return half, nil
+	}
+
+	// the level is 0 dB, which looks the same either way; models new enough to answer NRI use half steps
+	_, err := d.details(ctx)
+	if errors.Is(err, ErrNotAvailable) || errors.Is(err, ErrTimeout) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetChannelLevels gets the temporary level of every channel
+func (d *Device) GetChannelLevels() (ChannelLevels, error) {
+	return d.GetChannelLevelsContext(context.Background())
+}
+
+// GetChannelLevelsContext is GetChannelLevels with a context for cancellation and deadlines
+func (d *Device) GetChannelLevelsContext(ctx context.Context) (ChannelLevels, error) {
+	msg, err := d.SetGetOneContext(ctx, "CLV", "QSTN")
+	if err != nil {
+		return nil, err
+	}
+	v, ok := msg.Parsed.(ChannelLevels)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
+}
+
+// SetChannelLevel sets the temporary level of one channel in half dB steps, -12 to +12 dB.
+// CLV sets every channel at once, so the others are read first and sent back unchanged.
+func (d *Device) SetChannelLevel(ch Channel, db float64) (ChannelLevels, error) {
+	return d.SetChannelLevelContext(context.Background(), ch, db)
+}
+
+// SetChannelLevelContext is SetChannelLevel with a context for cancellation and deadlines
+func (d *Device) SetChannelLevelContext(ctx context.Context, ch Channel, db float64) (ChannelLevels, error) {
+	if db < -channelLevelRange || db > channelLevelRange {
+		return nil, fmt.Errorf("%s %.1f dB out of range %d to %d", ch, db, -channelLevelRange, channelLevelRange)
+	}
+	return d.adjustChannelLevel(ctx, ch, func(float64) float64 { return db })
+}
+
+// ChannelLevelUp raises one channel's level by half a dB
+func (d *Device) ChannelLevelUp(ch Channel) (ChannelLevels, error) {
+	return d.ChannelLevelUpContext(context.Background(), ch)
+}
+
+// ChannelLevelUpContext is ChannelLevelUp with a context for cancellation and deadlines
+func (d *Device) ChannelLevelUpContext(ctx context.Context, ch Channel) (ChannelLevels, error) {
+	return d.adjustChannelLevel(ctx, ch, func(db float64) float64 { return math.Min(db+0.5, channelLevelRange) })
+}
+
+// ChannelLevelDown lowers one channel's level by half a dB
+func (d *Device) ChannelLevelDown(ch Channel) (ChannelLevels, error) {
+	return d.ChannelLevelDownContext(context.Background(), ch)
+}
+
+// ChannelLevelDownContext is ChannelLevelDown with a context for cancellation and deadlines
+func (d *Device) ChannelLevelDownContext(ctx context.Context, ch Channel) (ChannelLevels, error) {
+	return d.adjustChannelLevel(ctx, ch, func(db float64) float64 { return math.Max(db-0.5, -channelLevelRange) })
+}
+
+func (d *Device) adjustChannelLevel(ctx context.Context, ch Channel, adjust func(float64) float64) (ChannelLevels, error) {
+	msg, err := d.SetGetOneContext(ctx, "CLV", "QSTN")
+	if err != nil {
+		return nil, err
+	}
+	levels, ok := msg.Parsed.(ChannelLevels)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	current, ok := levels[ch]
+	if !ok {
+		return nil, fmt.Errorf("channel %s: %w", ch, ErrUnsupported)
+	}
+
+	// send back every other channel exactly as the receiver reported it
+	i := 0
+	for Channels[i] != ch {
+		i++
+	}
+	value := msg.Response[:i*3] + formatChannelDB(adjust(current)) + msg.Response[(i+1)*3:]
+
+	msg, err = d.SetGetOneContext(ctx, "CLV", value)
+	if err != nil {
+		return nil, err
+	}
+	levels, ok = msg.Parsed.(ChannelLevels)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return levels, nil
+}
+
+// parseDB parses a level reply, e.g. "+3" is 3 dB on older models, "+06" is 3 dB on newer ones
+func parseDB(code, r string) (float64, error) {
+	v, err := parseLevel(r)
+	if err != nil {
+		return 0, &ProtocolError{Frame: []byte(code + r), Reason: err.Error()}
+	}
+	if len(r) == 3 {
+		return float64(v) / 2, nil
+	}
+	return float64(v), nil
+}
+
+// formatDB is the inverse of parseDB, rounding to the nearest step
+func formatDB(db float64, half bool) string {
+	if !half {
+		return formatLevel(int(math.Round(db)))
+	}
+	v := int(math.Round(db * 2))
+	if v == 0 {
+		return "00"
+	}
+	s := formatLevel(v)
+	if len(s) == 2 {
+		// always two hex digits in half step mode
+		s = s[:1] + "0" + s[1:]
+	}
+	return s
+}
+
+// parseCLV parses a CLV reply, three characters of half dB steps per channel in the order of Channels
+func parseCLV(r string) (ChannelLevels, error) {
+	if len(r)%3 != 0 {
+		return nil, &ProtocolError{Frame: []byte("CLV" + r), Reason: "invalid channel levels"}
+	}
+	levels := make(ChannelLevels)
+	for i, ch := range Channels {
+		if len(r) < (i+1)*3 {
+			break
+		}
+		field := r[i*3 : (i+1)*3]
+		v, err := parseLevel(strings.TrimPrefix(field, "0"))
+		if err != nil {
+			// channels the speaker setup does not use are not numbers
+			continue
+		}
+		levels[ch] = float64(v) / 2
+	}
+	return levels, nil
+}
+
+// formatChannelDB formats a CLV level, e.g. "+05" or "000"
+func formatChannelDB(db float64) string {
+	v := int(math.Round(db * 2))
+	if v == 0 {
+		return "000"
+	}
+	sign := "+"
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return sign + fmt.Sprintf("%02X", v)
+}
+
+// halfSteps reports whether code has been heard using half dB steps, and whether that is known
+func (l *liveState) halfSteps(code string) (bool, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	half, ok := l.levelSteps[code]
+	return half, ok
+}
+
+// learnSteps records which step size a level reply uses; 0 dB looks the same either way
+func (l *liveState) learnSteps(msg *Message) {
+	if msg.Response == "00" || len(msg.Response) < 2 {
+		return
+	}
+	if _, err := strconv.ParseInt(msg.Response[1:], 16, 8); err != nil {
+		return
+	}
+	if l.levelSteps == nil {
+		l.levelSteps = make(map[string]bool)
+	}
+	l.levelSteps[msg.Command] = len(msg.Response) == 3
+}
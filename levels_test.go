@@ -0,0 +1,132 @@
+package eiscp
+
+import (
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestDBRoundTrip(t *testing.T) {
+	tests := []struct {
+		value string
+		half  bool
+		db    float64
+	}{
+		{"00", true, 0},
+		{"+06", true, 3},
+		{"-03", true, -1.5},
+		{"+18", true, 12},
+		{"-1E", true, -15},
+		{"+01", true, 0.5},
+		{"+3", false, 3},
+		{"-C", false, -12},
+		{"+C", false, 12},
+		{"-F", false, -15},
+	}
+
+	for _, tt := range tests {
+		code := "SWL"
+		db, err := parseDB(code, tt.value)
+		if err != nil || db != tt.db {
+			t.Errorf("parseDB(%q) = %v, %v, want %v", tt.value, db, err, tt.db)
+		}
+		if got := formatDB(tt.db, tt.half); got != tt.value {
+			t.Errorf("formatDB(%v, %v) = %q, want %q", tt.db, tt.half, got, tt.value)
+		}
+	}
+}
+
+func TestFormatDBRounding(t *testing.T) {
+	tests := []struct {
+		db   float64
+		half bool
+		want string
+	}{
+		{2.4, false, "+2"},
+		{2.5, false, "+3"},
+		{-0.4, false, "00"},
+		{0.2, true, "00"},
+		{1.3, true, "+03"},
+		{-1.3, true, "-03"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDB(tt.db, tt.half); got != tt.want {
+			t.Errorf("formatDB(%v, %v) = %q, want %q", tt.db, tt.half, got, tt.want)
+		}
+	}
+}
+
+func TestParseCLV(t *testing.T) {
+	levels, err := parseCLV("000+05-0A000000000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChannelLevels{"FL": 0, "FR": 2.5, "C": -5, "SL": 0, "SR": 0, "SBL": 0, "SBR": 0, "SW": 0}
+	if len(levels) != len(want) {
+		t.Errorf("parseCLV() = %v, want %v", levels, want)
+	}
+	for ch, db := range want {
+		if levels[ch] != db {
+			t.Errorf("%s = %v, want %v", ch, levels[ch], db)
+		}
+	}
+
+	// channels the speaker setup does not use are left out
+	levels, err = parseCLV("000---+02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := levels["FR"]; ok || levels["C"] != 1 {
+		t.Errorf("parseCLV() with an unused channel = %v", levels)
+	}
+
+	if _, err := parseCLV("000+0"); err == nil {
+		t.Error("parseCLV() accepted a partial field")
+	}
+}
+
+func TestFormatChannelDB(t *testing.T) {
+	tests := []struct {
+		db   float64
+		want string
+	}{
+		{0, "000"},
+		{2.5, "+05"},
+		{-5, "-0A"},
+		{12, "+18"},
+		{-0.2, "000"},
+	}
+
+	for _, tt := range tests {
+		if got := formatChannelDB(tt.db); got != tt.want {
+			t.Errorf("formatChannelDB(%v) = %q, want %q", tt.db, got, tt.want)
+		}
+	}
+}
+
+func TestSetChannelLevel(t *testing.T) {
+	s := eiscptest.NewServer()
+	defer s.Close()
+	d, err := NewReceiverWithDialer(s.Dial, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// the front right is unused, and its field has to go back exactly as it came
+	s.Set("CLV", "+04----0A000000000000000")
+	if _, err := d.SetChannelLevel("C", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Get("CLV"); v != "+04---+03000000000000000" {
+		t.Errorf("CLV = %q after setting the center", v)
+	}
+
+	if _, err := d.ChannelLevelDown("SW"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Get("CLV"); v != "+04---+03000000000000-01" {
+		t.Errorf("CLV = %q after lowering the subwoofer", v)
+	}
+}
@@ -0,0 +1,98 @@
+package eiscp
+
+import (
+	"strconv"
+	"sync"
+)
+
+// menuPageSize is the number of lines the receiver sends per page of a list
+const menuPageSize = 10
+
+// NetworkMenu is a snapshot of the network service list the receiver is showing,
+// built from the NLT (title) and NLS (line) messages it sends
+type NetworkMenu struct {
+	Title    *NLT
+	Items    []*NLS // the lines on the current page, nil where a line has not been received
+	Cursor   int    // the line the cursor is on, -1 if there is no cursor
+	NumItems int    // the total number of items in the list, from the title
+}
+
+type menuState struct {
+	mu       sync.Mutex
+	title    *NLT
+	items    []*NLS
+	cursor   int
+	numItems int
+}
+
+// NetworkMenu returns a copy of the current network menu, safe to use while the device keeps updating it
+func (d *Device) NetworkMenu() NetworkMenu {
+	m := &d.menu
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nm := NetworkMenu{
+		Cursor:   -1,
+		NumItems: m.numItems,
+	}
+	if m.title == nil {
+		return nm
+	}
+	title := *m.title
+	nm.Title = &title
+	nm.Cursor = m.cursor
+	nm.Items = make([]*NLS, len(m.items))
+	for i, item := range m.items {
+		if item != nil {
+			line := *item
+			nm.Items[i] = &line
+		}
+	}
+	return nm
+}
+
+// update applies an NLT or NLS message, whenever we see an NLT, start over
+func (m *menuState) update(msg *Message) {
+	switch p := msg.Parsed.(type) {
+	case *NLT:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.title = p
+		m.cursor = -1
+		m.numItems = 0
+		if n, err := strconv.ParseUint(p.NumItems, 16, 16); err == nil {
+			m.numItems = int(n)
+		}
+		m.items = make([]*NLS, pageSize(m.numItems))
+	case *NLS:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.title == nil {
+			return
+		}
+		line, err := strconv.Atoi(p.LineInfo)
+		if err != nil {
+			line = -1 // "-" means no line, e.g. no cursor
+		}
+		if p.InfoType == "C" {
+			if p.Property == "P" {
+				// page information update, the lines which follow are a new page
+				m.items = make([]*NLS, pageSize(m.numItems))
+			}
+			m.cursor = line
+			return
+		}
+		if line < 0 || line >= len(m.items) {
+			return
+		}
+		m.items[line] = p
+	}
+}
+
+// pageSize is how many lines a page of a list with numItems items has
+func pageSize(numItems int) int {
+	if numItems > menuPageSize || numItems == 0 {
+		return menuPageSize
+	}
+	return numItems
+}
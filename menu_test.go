@@ -0,0 +1,78 @@
+package eiscp
+
+import (
+	"testing"
+)
+
+// feed parses each raw ISCP message and applies it to the menu
+func feed(t *testing.T, d *Device, raws ...string) {
+	t.Helper()
+	for _, raw := range raws {
+		var msg Message
+		if err := msg.ParseISCP([]byte(raw)); err != nil {
+			t.Fatalf("ParseISCP(%q): %v", raw, err)
+		}
+		d.menu.update(&msg)
+	}
+}
+
+// nlt builds a list title with numItems items
+func nlt(numItems, title string) string {
+	return "!1NLT" + "0E" + "0" + "1" + "0000" + numItems + "01" + "00" + "0E" + "00" + "00" + title
+}
+
+func TestNetworkMenu(t *testing.T) {
+	var d Device
+
+	// lines before any title are dropped
+	feed(t, &d, "!1NLSA0-stale")
+	if nm := d.NetworkMenu(); nm.Title != nil || nm.Items != nil || nm.Cursor != -1 {
+		t.Fatalf("menu before a title = %+v", nm)
+	}
+
+	feed(t, &d, nlt("0003", "Favorites"))
+	nm := d.NetworkMenu()
+	if nm.Title == nil || nm.Title.Title != "Favorites" || nm.NumItems != 3 || len(nm.Items) != 3 || nm.Cursor != -1 {
+		t.Fatalf("menu after NLT = %+v", nm)
+	}
+
+	feed(t, &d, "!1NLSC0P", "!1NLSA0-TuneIn", "!1NLSU1-Spotify", "!1NLSA5-past the end")
+	nm = d.NetworkMenu()
+	if nm.Cursor != 0 {
+		t.Errorf("cursor = %d, want 0", nm.Cursor)
+	}
+	if len(nm.Items) != 3 || nm.Items[0] == nil || nm.Items[0].Line != "TuneIn" ||
+		nm.Items[1] == nil || nm.Items[1].Line != "Spotify" || nm.Items[2] != nil {
+		t.Errorf("items = %+v", nm.Items)
+	}
+
+	// the snapshot is a copy
+	nm.Items[0].Line = "changed"
+	if d.NetworkMenu().Items[0].Line != "TuneIn" {
+		t.Error("changing the snapshot changed the menu")
+	}
+
+	// moving the cursor keeps the page, a page update clears it
+	feed(t, &d, "!1NLSC1C")
+	nm = d.NetworkMenu()
+	if nm.Cursor != 1 || nm.Items[0] == nil {
+		t.Errorf("after a cursor move: cursor %d, items %+v", nm.Cursor, nm.Items)
+	}
+	feed(t, &d, "!1NLSC-P")
+	nm = d.NetworkMenu()
+	if nm.Cursor != -1 || nm.Items[0] != nil || nm.Items[1] != nil {
+		t.Errorf("after a page update: cursor %d, items %+v", nm.Cursor, nm.Items)
+	}
+
+	// a new title starts over, long lists are sent a page at a time
+	feed(t, &d, "!1NLSA0-TuneIn", nlt("0020", "Stations"))
+	nm = d.NetworkMenu()
+	if nm.Title.Title != "Stations" || nm.NumItems != 32 || len(nm.Items) != menuPageSize || nm.Cursor != -1 {
+		t.Fatalf("menu after a new NLT = %+v", nm)
+	}
+	for i, item := range nm.Items {
+		if item != nil {
+			t.Errorf("item %d = %+v, want nil", i, item)
+		}
+	}
+}
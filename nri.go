@@ -1,7 +1,11 @@
 package eiscp
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
 )
 
 type NRI struct {
@@ -90,3 +94,65 @@ type NRI struct {
 		} `xml:"tuners"`
 	} `xml:"device"`
 }
+
+// details returns the NRI the device last heard, asking the receiver if it has not sent one yet
+func (d *Device) details(ctx context.Context) (*NRI, error) {
+	d.live.mu.Lock()
+	nri := d.live.details
+	d.live.mu.Unlock()
+	if nri != nil {
+		return nri, nil
+	}
+	return d.GetDetailsContext(ctx)
+}
+
+// controlRange is the range of values a control accepts
+type controlRange struct {
+	min  int
+	max  int
+	step int
+}
+
+// controlRange looks up a control in the NRI control list, e.g. "Bass". Controls the model has
+// disabled are refused with ErrUnsupported; def is used when the model does not list the control.
+func (d *Device) controlRange(ctx context.Context, id string, zone int, def controlRange) (controlRange, error) {
+	nri, err := d.details(ctx)
+	if errors.Is(err, ErrNotAvailable) || errors.Is(err, ErrTimeout) {
+		return def, nil
+	}
+	if err != nil {
+		return def, err
+	}
+
+	for _, c := range nri.Device.ControlList.Control {
+		if c.ID != id || (c.Zone != "" && c.Zone != strconv.Itoa(zone)) {
+			continue
+		}
+		if c.Value != "1" {
+			return def, fmt.Errorf("%s: %w", id, ErrUnsupported)
+		}
+		r := def
+		if v, err := strconv.Atoi(c.Min); err == nil {
+			r.min = v
+		}
+		if v, err := strconv.Atoi(c.Max); err == nil {
+			r.max = v
+		}
+		if v, err := strconv.Atoi(c.Step); err == nil && v > 0 {
+			r.step = v
+		}
+		return r, nil
+	}
+	return def, nil
+}
+
+// check returns an error if v is outside the range or between steps
+func (r controlRange) check(name string, v int) error {
+	if v < r.min || v > r.max {
+		return fmt.Errorf("%s %d out of range %d to %d", name, v, r.min, r.max)
+	}
+	if r.step > 1 && (v-r.min)%r.step != 0 {
+		return fmt.Errorf("%s %d is not a multiple of %d from %d", name, v, r.step, r.min)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package eiscp
+
+import (
+	"context"
+	"fmt"
+)
+
+// waiter is a persistent-mode request waiting for the reply with its command code
+type waiter struct {
+	mm   MultiMessage
+	done chan struct{}
+}
+
+// lockCode orders requests for the same command code, requests for different codes run concurrently.
+// Gives up if ctx is done while waiting.
+func (d *Device) lockCode(ctx context.Context, code string) error {
+	d.pendMu.Lock()
+	if d.codeLocks == nil {
+		d.codeLocks = make(map[string]chan struct{})
+	}
+	l, ok := d.codeLocks[code]
+	if !ok {
+		l = make(chan struct{}, 1)
+		d.codeLocks[code] = l
+	}
+	d.pendMu.Unlock()
+
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Device) unlockCode(code string) {
+	d.pendMu.Lock()
+	l := d.codeLocks[code]
+	d.pendMu.Unlock()
+	<-l
+}
+
+// addWaiter registers interest in the next message with code, the caller must hold the code's lock
+func (d *Device) addWaiter(code string) *waiter {
+	w := &waiter{
+		done: make(chan struct{}),
+	}
+
+	d.pendMu.Lock()
+	defer d.pendMu.Unlock()
+	if d.pending == nil {
+		d.pending = make(map[string]*waiter)
+	}
+	d.pending[code] = w
+	return w
+}
+
+func (d *Device) removeWaiter(code string, w *waiter) {
+	d.pendMu.Lock()
+	defer d.pendMu.Unlock()
+	if d.pending[code] == w {
+		delete(d.pending, code)
+	}
+}
+
+// route hands msg to the request waiting for its command code, if there is one
+func (d *Device) route(msg *Message) {
+	d.pendMu.Lock()
+	defer d.pendMu.Unlock()
+
+	w, ok := d.pending[msg.Command]
+	if !ok {
+		return
+	}
+	delete(d.pending, msg.Command)
+	w.mm.Messages = append(w.mm.Messages, msg)
+	close(w.done)
+}
+
+// failWaiters wakes every pending request when the connection is lost
+func (d *Device) failWaiters() {
+	d.pendMu.Lock()
+	defer d.pendMu.Unlock()
+
+	for code, w := range d.pending {
+		delete(d.pending, code)
+		close(w.done)
+	}
+}
+
+// setGetPersistent sends a command on a persistent connection and waits for the reply with the same code
+func (d *Device) setGetPersistent(ctx context.Context, command, arg string) (*MultiMessage, error) {
+	if err := d.lockCode(ctx, command); err != nil {
+		return nil, err
+	}
+	defer d.unlockCode(command)
+
+	// register before sending so the reply cannot be missed
+	w := d.addWaiter(command)
+	if err := d.writeCommand(ctx, command, arg); err != nil {
+		d.removeWaiter(command, w)
+		return nil, err
+	}
+
+	select {
+	case <-w.done:
+		if len(w.mm.Messages) == 0 {
+			return &w.mm, ErrNotConnected
+		}
+		return &w.mm, nil
+	case <-ctx.Done():
+		d.removeWaiter(command, w)
+		if ctx.Err() == context.Canceled {
+			return &MultiMessage{}, ctx.Err()
+		}
+		return &MultiMessage{}, fmt.Errorf("%w: no response to %s", ErrTimeout, command)
+	}
+}
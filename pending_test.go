@@ -0,0 +1,72 @@
+package eiscp_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	eiscp "github.com/cloudkucooland/go-onkyo"
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestConcurrentCodes(t *testing.T) {
+	s, d := receiver(t, true)
+	s.Script("MVL", eiscptest.Delay(300*time.Millisecond), eiscptest.Reply("MVL", "30"))
+
+	slow := make(chan error, 1)
+	go func() {
+		vol, err := d.GetVolume()
+		if err == nil && vol != 0x30 {
+			t.Errorf("GetVolume() = %d, want %d", vol, 0x30)
+		}
+		slow <- err
+	}()
+
+	// other codes are answered while the volume query is still waiting
+	start := time.Now()
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if power, err := d.GetPower(); err != nil || !power {
+			t.Errorf("GetPower() = %v, %v", power, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if src, err := d.GetSourceByCode(); err != nil || src != eiscp.Source("2B") {
+			t.Errorf("GetSourceByCode() = %v, %v", src, err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if muted, err := d.GetMute(); err != nil || muted {
+			t.Errorf("GetMute() = %v, %v", muted, err)
+		}
+	}()
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("other codes held up for %v by the volume query", elapsed)
+	}
+
+	if err := <-slow; err != nil {
+		t.Errorf("GetVolume(): %v", err)
+	}
+}
+
+func TestConcurrentSameCode(t *testing.T) {
+	_, d := receiver(t, true)
+
+	// requests for one code take turns, so each gets the reply to its own command
+	var wg sync.WaitGroup
+	for i := uint8(0x10); i < 0x20; i++ {
+		wg.Add(1)
+		go func(level uint8) {
+			defer wg.Done()
+			if vol, err := d.SetVolume(level); err != nil || vol != level {
+				t.Errorf("SetVolume(%d) = %d, %v", level, vol, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,186 @@
+package eiscp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxPresets is the number of tuner preset slots, PRS and PRM take 01-28 hex
+const maxPresets = 40
+
+// Preset is a stored tuner station
+type Preset struct {
+	Number    int       `json:"number"`
+	Name      string    `json:"name,omitempty"`
+	Frequency Frequency `json:"frequency"`
+}
+
+// Presets returns the stored presets from the receiver's NRI preset list, empty slots are left out
+func (t *Tuner) Presets() ([]Preset, error) {
+	return t.PresetsContext(context.Background())
+}
+
+// PresetsContext is Presets with a context for cancellation and deadlines
+func (t *Tuner) PresetsContext(ctx context.Context) ([]Preset, error) {
+	// always ask, the list changes whenever a preset is stored
+	nri, err := t.d.GetDetailsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var presets []Preset
+	for _, p := range nri.Device.PresetList.Preset {
+		n, err := strconv.ParseInt(p.ID, 16, 8)
+		if err != nil {
+			continue
+		}
+		f, ok := parsePresetFrequency(p.Band, p.Freq)
+		if !ok {
+			continue
+		}
+		presets = append(presets, Preset{Number: int(n), Name: strings.TrimSpace(p.Name), Frequency: f})
+	}
+	return presets, nil
+}
+
+// parsePresetFrequency parses an NRI preset; the band is 1 for FM and 2 for AM, 0 is an empty slot.
+// FM frequencies are in MHz, AM in kHz.
+func parsePresetFrequency(band, freq string) (Frequency, bool) {
+	var f Frequency
+	switch band {
+	case "1", "FM":
+		f.Band = BandFM
+	case "2", "AM":
+		f.Band = BandAM
+	default:
+		return f, false
+	}
+
+	khz, ok := nriKHz(f.Band, freq)
+	if !ok {
+		return f, false
+	}
+	f.KHz = khz
+	return f, true
+}
+
+// GetPreset gets the current preset number, 0 if the tuner is not on a preset
+func (t *Tuner) GetPreset() (int, error) {
+	return t.GetPresetContext(context.Background())
+}
+
+// GetPresetContext is GetPreset with a context for cancellation and deadlines
+func (t *Tuner) GetPresetContext(ctx context.Context) (int, error) {
+	p, err := t.d.GetPresetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(p, 16, 8)
+	if err != nil {
+		return 0, nil
+	}
+	return int(n), nil
+}
+
+// SelectPreset tunes to a preset by number, 1-40
+func (t *Tuner) SelectPreset(n int) (int, error) {
+	return t.SelectPresetContext(context.Background(), n)
+}
+
+// SelectPresetContext is SelectPreset with a context for cancellation and deadlines
+func (t *Tuner) SelectPresetContext(ctx context.Context, n int) (int, error) {
+	if n < 1 || n > maxPresets {
+		return 0, fmt.Errorf("preset %d out of range 1 to %d", n, maxPresets)
+	}
+	p, err := t.d.SetPresetContext(ctx, fmt.Sprintf("%02X", n))
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(p, 16, 8)
+	if err != nil {
+		return 0, &ProtocolError{Frame: []byte("PRS" + p), Reason: "invalid preset"}
+	}
+	return int(v), nil
+}
+
+// SelectPresetByName tunes to the preset with the given name, ignoring case
+func (t *Tuner) SelectPresetByName(name string) (int, error) {
+	return t.SelectPresetByNameContext(context.Background(), name)
+}
+
+// SelectPresetByNameContext is SelectPresetByName with a context for cancellation and deadlines
+func (t *Tuner) SelectPresetByNameContext(ctx context.Context, name string) (int, error) {
+	presets, err := t.PresetsContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return t.SelectPresetContext(ctx, p.Number)
+		}
+	}
+	return 0, fmt.Errorf("no preset named %q", name)
+}
+
+// StorePreset stores the current station in a preset slot, 1-40
+func (t *Tuner) StorePreset(n int) error {
+	return t.StorePresetContext(context.Background(), n)
+}
+
+// StorePresetContext is StorePreset with a context for cancellation and deadlines
+func (t *Tuner) StorePresetContext(ctx context.Context, n int) error {
+	if n < 1 || n > maxPresets {
+		return fmt.Errorf("preset %d out of range 1 to %d", n, maxPresets)
+	}
+	return t.d.SetOnlyContext(ctx, "PRM", fmt.Sprintf("%02X", n))
+}
+
+// ExportPresets writes the preset table to w as JSON
+func (t *Tuner) ExportPresets(w io.Writer) error {
+	return t.ExportPresetsContext(context.Background(), w)
+}
+
+// ExportPresetsContext is ExportPresets with a context for cancellation and deadlines
+func (t *Tuner) ExportPresetsContext(ctx context.Context, w io.Writer) error {
+	presets, err := t.PresetsContext(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(presets)
+}
+
+// ImportPresets reads a preset table written by ExportPresets and stores each preset by tuning to it.
+// Names cannot be set over ISCP, the receiver keeps whatever names its slots already have.
+// The tuner is left on the last preset stored.
+func (t *Tuner) ImportPresets(r io.Reader) error {
+	return t.ImportPresetsContext(context.Background(), r)
+}
+
+// ImportPresetsContext is ImportPresets with a context for cancellation and deadlines
+func (t *Tuner) ImportPresetsContext(ctx context.Context, r io.Reader) error {
+	var presets []Preset
+	if err := json.NewDecoder(r).Decode(&presets); err != nil {
+		return err
+	}
+
+	for _, p := range presets {
+		if p.Number < 1 || p.Number > maxPresets {
+			return fmt.Errorf("preset %d out of range 1 to %d", p.Number, maxPresets)
+		}
+	}
+	for _, p := range presets {
+		if _, err := t.SetFrequencyContext(ctx, p.Frequency); err != nil {
+			return fmt.Errorf("preset %d: %w", p.Number, err)
+		}
+		if err := t.StorePresetContext(ctx, p.Number); err != nil {
+			return fmt.Errorf("preset %d: %w", p.Number, err)
+		}
+	}
+	return nil
+}
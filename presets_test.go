@@ -0,0 +1,120 @@
+package eiscp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestParsePresetFrequency(t *testing.T) {
+	tests := []struct {
+		band, freq string
+		want       Frequency
+		ok         bool
+	}{
+		{"1", "101.10", FM(101.1), true},
+		{"1", "87.5", FM(87.5), true},
+		{"FM", "107.9", FM(107.9), true},
+		{"2", "810", AM(810), true},
+		{"AM", " 1710 ", AM(1710), true},
+		{"0", "0", Frequency{}, false},
+		{"1", "", Frequency{}, false},
+		{"3", "101.1", Frequency{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parsePresetFrequency(tt.band, tt.freq)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parsePresetFrequency(%q, %q) = %v, %v, want %v, %v", tt.band, tt.freq, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// testTuner connects a one-shot device to a new fake receiver
+func testTuner(t *testing.T) (*eiscptest.Server, *Device) {
+	t.Helper()
+	s := eiscptest.NewServer()
+	d, err := NewReceiverWithDialer(s.Dial, false)
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+		s.Close()
+	})
+	return s, d
+}
+
+func TestPresetsRoundTrip(t *testing.T) {
+	_, from := testTuner(t)
+	var buf bytes.Buffer
+	if err := from.Tuner().ExportPresets(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var exported []Preset
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	want := []Preset{
+		{Number: 1, Name: "KUOW", Frequency: FM(101.1)},
+		{Number: 2, Name: "KGO", Frequency: AM(810)},
+	}
+	if len(exported) != len(want) || exported[0] != want[0] || exported[1] != want[1] {
+		t.Fatalf("exported %+v, want %+v", exported, want)
+	}
+
+	s, to := testTuner(t)
+	if err := to.Tuner().ImportPresets(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// PRM is not answered, a query makes sure the receiver has seen the last one
+	if _, err := to.GetPower(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, cmd := range s.Received() {
+		if (strings.HasPrefix(cmd, "TUN") || strings.HasPrefix(cmd, "PRM")) && !strings.HasSuffix(cmd, "QSTN") {
+			got = append(got, cmd)
+		}
+	}
+	wantCmds := []string{"TUN10110", "PRM01", "TUN00810", "PRM02"}
+	if strings.Join(got, " ") != strings.Join(wantCmds, " ") {
+		t.Errorf("import sent %q, want %q", got, wantCmds)
+	}
+}
+
+func TestImportPresetsRejectsBadNumbers(t *testing.T) {
+	s, d := testTuner(t)
+	err := d.Tuner().ImportPresets(strings.NewReader(`[{"number":1,"frequency":{"band":"FM","khz":101100}},{"number":41,"frequency":{"band":"AM","khz":810}}]`))
+	if err == nil {
+		t.Fatal("ImportPresets() accepted preset 41")
+	}
+	// nothing is stored unless every preset is valid
+	for _, cmd := range s.Received() {
+		if strings.HasPrefix(cmd, "PRM") || strings.HasPrefix(cmd, "TUN") {
+			t.Errorf("sent %s", cmd)
+		}
+	}
+}
+
+func TestSelectPresetByName(t *testing.T) {
+	s, d := testTuner(t)
+
+	n, err := d.Tuner().SelectPresetByName("kgo")
+	if err != nil || n != 2 {
+		t.Errorf("SelectPresetByName(kgo) = %d, %v", n, err)
+	}
+	if v, _ := s.Get("PRS"); v != "02" {
+		t.Errorf("PRS is %q, want 02", v)
+	}
+
+	if _, err := d.Tuner().SelectPresetByName("KEXP"); err == nil {
+		t.Error("SelectPresetByName(KEXP) found a preset")
+	}
+}
@@ -0,0 +1,308 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RadioService is a channel-based tuner: XM, Sirius or HD Radio
+type RadioService struct {
+	d     *Device
+	codes radioCodes
+}
+
+// radioCodes are the command codes for a radio service
+type radioCodes struct {
+	channel string
+	name    string
+	artist  string
+	title   string
+	format  string // how the channel number is sent
+	max     int
+}
+
+var (
+	xmCodes     = radioCodes{channel: "XCH", name: "XCN", artist: "XAT", title: "XTI", format: "%03d", max: 597}
+	siriusCodes = radioCodes{channel: "SCH", name: "SCN", artist: "SAT", title: "STI", format: "%03d", max: 597}
+	hdCodes     = radioCodes{channel: "HPR", name: "HCN", artist: "HAT", title: "HTI", format: "%d", max: 8}
+)
+
+// RadioInfo is what a radio service is playing, fields the receiver does not report are empty
+type RadioInfo struct {
+	Channel     int
+	ChannelName string
+	Artist      string
+	Title       string
+}
+
+// XM returns a handle for the XM satellite tuner
+func (d *Device) XM() *RadioService {
+	return &RadioService{d: d, codes: xmCodes}
+}
+
+// Sirius returns a handle for the Sirius satellite tuner
+func (d *Device) Sirius() *RadioService {
+	return &RadioService{d: d, codes: siriusCodes}
+}
+
+// HDRadio returns a handle for the HD Radio tuner, the channel is the HD program, 1-8
+func (d *Device) HDRadio() *RadioService {
+	return &RadioService{d: d, codes: hdCodes}
+}
+
+// GetChannel gets the current channel number
+func (r *RadioService) GetChannel() (int, error) {
+	return r.GetChannelContext(context.Background())
+}
+
+// GetChannelContext is GetChannel with a context for cancellation and deadlines
+func (r *RadioService) GetChannelContext(ctx context.Context) (int, error) {
+	return r.setChannel(ctx, "QSTN")
+}
+
+// SetChannel tunes to a channel number, 0-597 for XM and Sirius, 1-8 for HD Radio
+func (r *RadioService) SetChannel(n int) (int, error) {
+	return r.SetChannelContext(context.Background(), n)
+}
+
+// SetChannelContext is SetChannel with a context for cancellation and deadlines
+func (r *RadioService) SetChannelContext(ctx context.Context, n int) (int, error) {
+	if n < 0 || n > r.codes.max {
+		return 0, fmt.Errorf("channel %d out of range 0 to %d", n, r.codes.max)
+	}
+	return r.setChannel(ctx, fmt.Sprintf(r.codes.format, n))
+}
+
+// ChannelUp tunes to the next channel
+func (r *RadioService) ChannelUp() (int, error) {
+	return r.ChannelUpContext(context.Background())
+}
+
+// ChannelUpContext is ChannelUp with a context for cancellation and deadlines
+func (r *RadioService) ChannelUpContext(ctx context.Context) (int, error) {
+	return r.setChannel(ctx, "UP")
+}
+
+// ChannelDown tunes to the previous channel
+func (r *RadioService) ChannelDown() (int, error) {
+	return r.ChannelDownContext(context.Background())
+}
+
+// ChannelDownContext is ChannelDown with a context for cancellation and deadlines
+func (r *RadioService) ChannelDownContext(ctx context.Context) (int, error) {
+	return r.setChannel(ctx, "DOWN")
+}
+
+// Info gets the channel number, channel name, artist and title
+func (r *RadioService) Info() (*RadioInfo, error) {
+	return r.InfoContext(context.Background())
+}
+
+// InfoContext is Info with a context for cancellation and deadlines
+func (r *RadioService) InfoContext(ctx context.Context) (*RadioInfo, error) {
+	var info RadioInfo
+	var err error
+	if info.Channel, err = r.GetChannelContext(ctx); err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		code string
+		dst  *string
+	}{
+		{r.codes.name, &info.ChannelName},
+		{r.codes.artist, &info.Artist},
+		{r.codes.title, &info.Title},
+	} {
+		msg, err := r.d.SetGetOneContext(ctx, f.code, "QSTN")
+		if errors.Is(err, ErrNotAvailable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		v, ok := msg.Parsed.(string)
+		if !ok {
+			return nil, unexpected(msg)
+		}
+		*f.dst = v
+	}
+	return &info, nil
+}
+
+func (r *RadioService) setChannel(ctx context.Context, value string) (int, error) {
+	msg, err := r.d.SetGetOneContext(ctx, r.codes.channel, value)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := msg.Parsed.(int)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
+}
+
+// DAB controls the DAB digital radio tuner. Stations are selected by preset.
+type DAB struct {
+	d *Device
+}
+
+// DAB returns a handle for the DAB tuner
+func (d *Device) DAB() *DAB {
+	return &DAB{d: d}
+}
+
+// StationName gets the name of the current DAB station
+func (b *DAB) StationName() (string, error) {
+	return b.StationNameContext(context.Background())
+}
+
+// StationNameContext is StationName with a context for cancellation and deadlines
+func (b *DAB) StationNameContext(ctx context.Context) (string, error) {
+	msg, err := b.d.SetGetOneContext(ctx, "DSN", "QSTN")
+	if err != nil {
+		return "", err
+	}
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
+}
+
+// DABDisplay is what the DAB display info (UDD) is showing
+type DABDisplay struct {
+	Mode DABDisplayMode
+	Text string
+}
+
+// DABDisplayMode selects the DAB display info
+type DABDisplayMode string
+
+// DAB display modes
+const (
+	DABProgramType        DABDisplayMode = "PT"
+	DABBitRate            DABDisplayMode = "AT" // bit rate and audio type
+	DABMultiplexName      DABDisplayMode = "MN" // the ensemble name
+	DABMultiplexFrequency DABDisplayMode = "MF" // ensemble name and frequency
+)
+
+// EnsembleName gets the name of the DAB ensemble (multiplex) the station is on.
+// It switches the DAB display info to show the multiplex name.
+func (b *DAB) EnsembleName() (string, error) {
+	return b.EnsembleNameContext(context.Background())
+}
+
+// EnsembleNameContext is EnsembleName with a context for cancellation and deadlines
+func (b *DAB) EnsembleNameContext(ctx context.Context) (string, error) {
+	info, err := b.SetDisplayContext(ctx, DABMultiplexName)
+	if err != nil {
+		return "", err
+	}
+	return info.Text, nil
+}
+
+// GetDisplay gets the DAB display info
+func (b *DAB) GetDisplay() (*DABDisplay, error) {
+	return b.GetDisplayContext(context.Background())
+}
+
+// GetDisplayContext is GetDisplay with a context for cancellation and deadlines
+func (b *DAB) GetDisplayContext(ctx context.Context) (*DABDisplay, error) {
+	return b.SetDisplayContext(ctx, "QSTN")
+}
+
+// SetDisplay chooses what the DAB display info shows, and returns it
+func (b *DAB) SetDisplay(mode DABDisplayMode) (*DABDisplay, error) {
+	return b.SetDisplayContext(context.Background(), mode)
+}
+
+// SetDisplayContext is SetDisplay with a context for cancellation and deadlines
+func (b *DAB) SetDisplayContext(ctx context.Context, mode DABDisplayMode) (*DABDisplay, error) {
+	msg, err := b.d.SetGetOneContext(ctx, "UDD", string(mode))
+	if err != nil {
+		return nil, err
+	}
+	v, ok := msg.Parsed.(*DABDisplay)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
+}
+
+// parseUDD parses the DAB display info, the mode followed by its text, e.g. "MNBBC National DAB"
+func parseUDD(r string) *DABDisplay {
+	if len(r) >= 2 {
+		switch mode := DABDisplayMode(r[:2]); mode {
+		case DABProgramType, DABBitRate, DABMultiplexName, DABMultiplexFrequency:
+			return &DABDisplay{Mode: mode, Text: strings.TrimSpace(r[2:])}
+		}
+	}
+	return &DABDisplay{Text: strings.TrimSpace(r)}
+}
+
+// GetPreset gets the current DAB preset number
+func (b *DAB) GetPreset() (int, error) {
+	return b.GetPresetContext(context.Background())
+}
+
+// GetPresetContext is GetPreset with a context for cancellation and deadlines
+func (b *DAB) GetPresetContext(ctx context.Context) (int, error) {
+	return b.setPreset(ctx, "QSTN")
+}
+
+// SelectPreset tunes to a DAB preset, 1-40
+func (b *DAB) SelectPreset(n int) (int, error) {
+	return b.SelectPresetContext(context.Background(), n)
+}
+
+// SelectPresetContext is SelectPreset with a context for cancellation and deadlines
+func (b *DAB) SelectPresetContext(ctx context.Context, n int) (int, error) {
+	if n < 1 || n > maxPresets {
+		return 0, fmt.Errorf("preset %d out of range 1 to %d", n, maxPresets)
+	}
+	return b.setPreset(ctx, fmt.Sprintf("%02X", n))
+}
+
+// PresetUp tunes to the next DAB preset
+func (b *DAB) PresetUp() (int, error) {
+	return b.PresetUpContext(context.Background())
+}
+
+// PresetUpContext is PresetUp with a context for cancellation and deadlines
+func (b *DAB) PresetUpContext(ctx context.Context) (int, error) {
+	return b.setPreset(ctx, "UP")
+}
+
+// PresetDown tunes to the previous DAB preset
+func (b *DAB) PresetDown() (int, error) {
+	return b.PresetDownContext(context.Background())
+}
+
+// PresetDownContext is PresetDown with a context for cancellation and deadlines
+func (b *DAB) PresetDownContext(ctx context.Context) (int, error) {
+	return b.setPreset(ctx, "DOWN")
+}
+
+func (b *DAB) setPreset(ctx context.Context, value string) (int, error) {
+	msg, err := b.d.SetGetOneContext(ctx, "DPS", value)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := msg.Parsed.(int)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
+}
+
+// parseChannel parses a channel or preset number in the given base
+func parseChannel(code, r string, base int) (int, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(r), base, 16)
+	if err != nil {
+		return 0, &ProtocolError{Frame: []byte(code + r), Reason: "invalid channel"}
+	}
+	return int(v), nil
+}
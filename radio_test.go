@@ -0,0 +1,41 @@
+package eiscp
+
+import (
+	"testing"
+)
+
+func TestSetChannel(t *testing.T) {
+	s, d := testTuner(t)
+
+	tests := []struct {
+		r    *RadioService
+		code string
+		n    int
+		want string
+		err  bool
+	}{
+		{d.XM(), "XCH", 300, "300", false},
+		{d.XM(), "XCH", 7, "007", false},
+		{d.Sirius(), "SCH", 597, "597", false},
+		{d.Sirius(), "SCH", 598, "", true},
+		{d.HDRadio(), "HPR", 2, "2", false},
+		{d.HDRadio(), "HPR", 9, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.r.SetChannel(tt.n)
+		if (err != nil) != tt.err {
+			t.Errorf("%s SetChannel(%d) error = %v", tt.code, tt.n, err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if got != tt.n {
+			t.Errorf("%s SetChannel(%d) = %d", tt.code, tt.n, got)
+		}
+		if v, _ := s.Get(tt.code); v != tt.want {
+			t.Errorf("%s is %q, want %q", tt.code, v, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package eiscp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RDSMode is what the front display shows from the FM station's RDS data
+type RDSMode string
+
+// RDS display modes
+const (
+	RDSRadioText      RDSMode = "00"
+	RDSProgramType    RDSMode = "01"
+	RDSTrafficProgram RDSMode = "02"
+)
+
+// TunerStatus is what the tuner is playing. RDS fields are read off the front display,
+// so each is only filled in once the display has shown it; see SetRDSMode.
+// The station name is what the display shows before an RDS mode is chosen. Volume, input and
+// listening mode banners share the display, so text which could be one of those is not taken as a name.
+type TunerStatus struct {
+	Frequency   Frequency
+	Preset      int // 0 if not on a preset
+	RDSMode     RDSMode
+	PSName      string // station name, up to 8 characters
+	RadioText   string
+	ProgramType string
+}
+
+// rdsState is the RDS data collected from the display, it is cleared when the station changes
+type rdsState struct {
+	mode        RDSMode
+	tuned       string // the TUN value the data belongs to
+	psName      string
+	radioText   string
+	programType string
+	noFLD       bool // the receiver did not answer FLD, Status stops asking
+}
+
+// fldTimeout bounds the display query in Status, some models never answer it
+const fldTimeout = time.Second
+
+// maxPSName is the length of an RDS program service name
+const maxPSName = 8
+
+// SetRDSMode switches the front display to show the radio text, program type or traffic program flag.
+// The tuner does not report RDS data any other way, Status reads it back from the display.
+func (t *Tuner) SetRDSMode(mode RDSMode) error {
+	return t.SetRDSModeContext(context.Background(), mode)
+}
+
+// SetRDSModeContext is SetRDSMode with a context for cancellation and deadlines
+func (t *Tuner) SetRDSModeContext(ctx context.Context, mode RDSMode) error {
+	if err := t.d.SetOnlyContext(ctx, "RDS", string(mode)); err != nil {
+		return err
+	}
+	// the receiver does not echo RDS, so remember it here
+	t.d.live.mu.Lock()
+	t.d.live.rds.mode = mode
+	t.d.live.mu.Unlock()
+	return nil
+}
+
+// Status gets the frequency, preset and RDS information. The display is read to pick up whichever
+// RDS field it is currently showing; fields shown earlier for the same station are kept.
+func (t *Tuner) Status() (*TunerStatus, error) {
+	return t.StatusContext(context.Background())
+}
+
+// StatusContext is Status with a context for cancellation and deadlines
+func (t *Tuner) StatusContext(ctx context.Context) (*TunerStatus, error) {
+	f, err := t.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preset, err := t.GetPresetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.d.live.mu.Lock()
+	askFLD := !t.d.live.rds.noFLD
+	t.d.live.mu.Unlock()
+	if f.Band == BandFM && askFLD {
+		// not every model answers FLD, then there is simply no fresh RDS
+		fctx, cancel := context.WithTimeout(ctx, fldTimeout)
+		_, err := t.d.GetFLInformationContext(fctx)
+		cancel()
+		if errors.Is(err, ErrTimeout) || errors.Is(err, ErrNotAvailable) {
+			t.d.live.mu.Lock()
+			t.d.live.rds.noFLD = true
+			t.d.live.mu.Unlock()
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	t.d.live.mu.Lock()
+	defer t.d.live.mu.Unlock()
+	rds := t.d.live.rds
+	status := TunerStatus{
+		Frequency: *f,
+		Preset:    preset,
+		RDSMode:   rds.mode,
+	}
+	if rds.tuned == f.tun() {
+		status.PSName = rds.psName
+		status.RadioText = rds.radioText
+		status.ProgramType = rds.programType
+	}
+	return &status, nil
+}
+
+// parseFLD decodes the front display text, which is sent as hex
+func parseFLD(r string) string {
+	text, err := hex.DecodeString(r)
+	if err != nil {
+		return r
+	}
+	return strings.TrimSpace(string(text))
+}
+
+// updateRDS files display text under the RDS field the display is showing, returning an event if it changed
+func (l *liveState) updateRDS(msg *Message) Event {
+	switch msg.Command {
+	case "TUN":
+		if msg.Response == l.rds.tuned {
+			return nil
+		}
+		old := l.rds
+		l.rds = rdsState{mode: old.mode, tuned: msg.Response, noFLD: old.noFLD}
+		if old.psName == "" && old.radioText == "" && old.programType == "" {
+			return nil
+		}
+		return RDSChanged{}
+	case "RDS":
+		l.rds.mode = RDSMode(msg.Response)
+		return nil
+	}
+
+	// FLD: only the FM tuner's display carries RDS
+	text, ok := msg.Parsed.(string)
+	if !ok || text == "" || l.rds.tuned == "" || !isFMSource(l.state.Source) {
+		return nil
+	}
+	old := l.rds
+	switch l.rds.mode {
+	case RDSRadioText:
+		l.rds.radioText = text
+	case RDSProgramType:
+		l.rds.programType = text
+	case "":
+		// the display's default is the station name, once the station has sent one
+		if !isPSName(text) {
+			return nil
+		}
+		l.rds.psName = text
+	default:
+		return nil
+	}
+	if l.rds == old {
+		return nil
+	}
+	return RDSChanged{PSName: l.rds.psName, RadioText: l.rds.radioText, ProgramType: l.rds.programType}
+}
+
+// isPSName reports whether display text can be a station name rather than the frequency or a banner
+func isPSName(text string) bool {
+	if len(text) > maxPSName {
+		// "Volume 40", "FM 101.10MHz" and most listening modes are longer than a name can be
+		return false
+	}
+	if _, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(text, "MHz")), 64); err == nil {
+		return false
+	}
+	if strings.EqualFold(text, "Muting") {
+		return false
+	}
+	if _, ok := SourceByName[strings.ToLower(text)]; ok {
+		return false
+	}
+	for _, name := range SourceToName {
+		if strings.EqualFold(text, name) {
+			return false
+		}
+	}
+	for _, name := range ListeningModes {
+		if strings.EqualFold(text, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFMSource reports whether src can be the FM tuner, an unknown source is given the benefit of the doubt
+func isFMSource(src Source) bool {
+	return src == "" || src == bandSources[BandFM] || src == "26"
+}
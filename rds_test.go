@@ -0,0 +1,112 @@
+package eiscp
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestIsPSName(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"KUOW", true},
+		{"BBC R4", true},
+		{"KISS 107", true},
+		{"Volume 40", false},
+		{"FM 101.10MHz", false},
+		{"101.10", false},
+		{"101.10MHz", false},
+		{"Muting", false},
+		{"TUNER", false},
+		{"Stereo", false},
+		{"direct", false},
+		{"A Long Station", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPSName(tt.text); got != tt.want {
+			t.Errorf("isPSName(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// fld is the display text as the receiver sends it
+func fld(text string) string {
+	return strings.ToUpper(hex.EncodeToString([]byte(text)))
+}
+
+func TestTunerStatus(t *testing.T) {
+	s, d := testTuner(t)
+	s.Set("SLI", "24")
+	tuner := d.Tuner()
+
+	// a banner is not a name
+	s.Set("FLD", fld("Volume 40"))
+	ts, err := tuner.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.Frequency != FM(101.1) || ts.Preset != 1 || ts.PSName != "" {
+		t.Errorf("Status() = %+v", ts)
+	}
+
+	s.Set("FLD", fld("KUOW    "))
+	if ts, err = tuner.Status(); err != nil || ts.PSName != "KUOW" {
+		t.Errorf("Status() = %+v, %v, want station KUOW", ts, err)
+	}
+
+	// the display now shows radio text, the name is kept
+	if err := tuner.SetRDSMode(RDSRadioText); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("FLD", fld("Morning Edition"))
+	if ts, err = tuner.Status(); err != nil || ts.PSName != "KUOW" || ts.RadioText != "Morning Edition" {
+		t.Errorf("Status() = %+v, %v", ts, err)
+	}
+
+	// a new station starts over
+	if _, err := tuner.SetFrequency(FM(94.9)); err != nil {
+		t.Fatal(err)
+	}
+	if ts, err = tuner.Status(); err != nil || ts.PSName != "" || ts.RadioText != "Morning Edition" {
+		t.Errorf("Status() after tuning = %+v, %v", ts, err)
+	}
+}
+
+func TestTunerStatusWithoutFLD(t *testing.T) {
+	s, d := testTuner(t)
+	s.Set("SLI", "24")
+	s.Script("FLD", eiscptest.Delay(1500*time.Millisecond))
+	tuner := d.Tuner()
+
+	start := time.Now()
+	if _, err := tuner.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("Status() waited %v for FLD", elapsed)
+	}
+
+	// once unanswered, the display is not asked again
+	start = time.Now()
+	if _, err := tuner.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("second Status() took %v", elapsed)
+	}
+	asked := 0
+	for _, cmd := range s.Received() {
+		if cmd == "FLDQSTN" {
+			asked++
+		}
+	}
+	if asked != 1 {
+		t.Errorf("FLD asked %d times, want once", asked)
+	}
+}
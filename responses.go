@@ -2,35 +2,28 @@ package eiscp
 
 import (
 	"encoding/xml"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
-// bundle these together, whenever we see an NLT, start over.
-type menu struct {
-	NLT *NLT
-	NLS []*NLS
-}
-
-var Menu menu
-
 func (r *Message) parseResponseValue() (interface{}, error) {
 	switch r.Command {
-	case "SLI":
+	case "SLI", "SLZ", "SL3", "SL4":
 		s, ok := SourceToName[Source(r.Response)]
 		if !ok {
 			s = "unknown"
 		}
 		return s, nil
-	case "PWR":
+	case "PWR", "ZPW", "PW3", "PW4":
 		return r.Response == "01", nil
-	case "MVL":
+	case "MVL", "ZVL", "VL3", "VL4":
 		vol, err := strconv.ParseUint(r.Response, 16, 8)
 		if err != nil {
 			return 0, err
 		}
 		return uint8(vol), nil
-	case "AMT":
+	case "AMT", "ZMT", "MT3", "MT4":
 		return r.Response == "01", nil
 	case "NRI":
 		var nri NRI
@@ -64,6 +57,9 @@ func (r *Message) parseResponseValue() (interface{}, error) {
 		}
 		return true, nil
 	case "NLT":
+		if len(r.Response) < 22 {
+			return nil, r.short(22)
+		}
 		var nlt NLT
 		nlt.ServiceType = NetSource(r.Response[0:2])
 		nlt.UIType = r.Response[2:3]
@@ -75,21 +71,22 @@ func (r *Message) parseResponseValue() (interface{}, error) {
 		nlt.IconR = NetSource(r.Response[18:20])
 		nlt.Status = r.Response[20:22]
 		nlt.Title = r.Response[22:len(r.Response)]
-
-		// reset the menu
-		Menu.NLS = nil
-		Menu.NLT = &nlt
 		return &nlt, nil
 	case "NLS":
+		if len(r.Response) < 3 {
+			return nil, r.short(3)
+		}
 		var nls NLS
 		nls.InfoType = r.Response[0:1]
 		nls.LineInfo = r.Response[1:2]
 		nls.Property = r.Response[2:3]
 		nls.Line = r.Response[3:len(r.Response)]
-		Menu.NLS = append(Menu.NLS, &nls)
 		return &nls, nil
 	case "TPD":
 		// "F100C 38"
+		if len(r.Response) < 8 {
+			return uint8(38), r.short(8)
+		}
 		sub := r.Response[6:8]
 		if sub == "" || sub == " 0" {
 			return uint8(38), nil
@@ -100,8 +97,34 @@ func (r *Message) parseResponseValue() (interface{}, error) {
 			return uint8(38), err
 		}
 		return uint8(tempC), nil
-	case "PRS":
+	case "PRS", "PRZ", "PR3", "PR4", "TUZ", "TU3", "TU4":
 		return r.Response, nil
+	case "IFA":
+		return parseIFA(r.Response), nil
+	case "TUN":
+		return parseTUN(r.Response)
+	case "XCH", "SCH", "HPR":
+		return parseChannel(r.Command, r.Response, 10)
+	case "DPS":
+		return parseChannel(r.Command, r.Response, 16)
+	case "UDD":
+		return parseUDD(r.Response), nil
+	case "XCN", "XAT", "XTI", "SCN", "SAT", "STI", "HCN", "HAT", "HTI", "DSN":
+		return strings.TrimSpace(r.Response), nil
+	case "FLD":
+		return parseFLD(r.Response), nil
+	case "SWL", "SW2", "CTL":
+		return parseDB(r.Command, r.Response)
+	case "CLV":
+		return parseCLV(r.Response)
+	case "ZTN", "TN3", "TFR", "TFW", "TCT", "TSR", "TSB", "THT":
+		return parseTone(r.Command, r.Response)
+	case "ZBL", "BL3":
+		bal, err := parseLevel(r.Response)
+		if err != nil {
+			return 0, &ProtocolError{Frame: []byte(r.Command + r.Response), Reason: err.Error()}
+		}
+		return bal, nil
 	case "NDS":
 		return parseNDS(r.Response)
 	case "NST":
@@ -144,8 +167,14 @@ func (r *Message) parseResponseValue() (interface{}, error) {
 	default:
 		return r.Response, nil
 	}
-	// not reached
-	return nil, nil
+}
+
+// short is the error for a value too short to hold the fixed-width fields of its command
+func (r *Message) short(want int) error {
+	return &ProtocolError{
+		Frame:  []byte(r.Command + r.Response),
+		Reason: fmt.Sprintf("value too short: %d bytes, want at least %d", len(r.Response), want),
+	}
 }
 
 var DimmerState = map[string]string{
@@ -158,6 +187,9 @@ var DimmerState = map[string]string{
 }
 
 func parseNDS(r string) (*NetworkStatus, error) {
+	if len(r) < 3 {
+		return nil, &ProtocolError{Frame: []byte("NDS" + r), Reason: "value too short"}
+	}
 	var ns NetworkStatus
 	switch r[0:1] {
 	case "-":
@@ -208,6 +240,9 @@ func parseNDS(r string) (*NetworkStatus, error) {
 }
 
 func parseNST(r string) (*NetworkPlayStatus, error) {
+	if len(r) < 3 {
+		return nil, &ProtocolError{Frame: []byte("NST" + r), Reason: "value too short"}
+	}
 	var nps NetworkPlayStatus
 	switch r[0:1] {
 	case "S":
@@ -258,6 +293,9 @@ func parseNST(r string) (*NetworkPlayStatus, error) {
 
 func parseNMS(r string) (*NetworkMenuStatus, error) {
 	// Mxxxxx20e
+	if len(r) < 7 {
+		return nil, &ProtocolError{Frame: []byte("NMS" + r), Reason: "value too short"}
+	}
 	var nms NetworkMenuStatus
 	if r[0:1] == "M" {
 		nms.Menu = true
@@ -0,0 +1,143 @@
+package eiscp_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	eiscp "github.com/cloudkucooland/go-onkyo"
+)
+
+// serialPeer is a receiver on the other end of an RS-232 line. Models disagree on how messages end,
+// so each reply uses the next of the endings seen in the wild.
+type serialPeer struct {
+	mu      sync.Mutex
+	state   map[string]string
+	endings []string
+	next    int
+	w       *io.PipeWriter
+}
+
+// port is the device's end of the line
+type port struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+}
+
+func (p port) Close() error {
+	for _, c := range p.closers {
+		c.Close()
+	}
+	return nil
+}
+
+// serialLine connects a Device to a new serialPeer over a pair of io.Pipes, which have no read deadlines
+func serialLine(t *testing.T, persistent bool) (*serialPeer, *eiscp.Device) {
+	t.Helper()
+	devR, peerW := io.Pipe()
+	peerR, devW := io.Pipe()
+
+	p := &serialPeer{
+		state:   map[string]string{"PWR": "01", "MVL": "20", "AMT": "00", "SLI": "2B"},
+		endings: []string{"\x1a", "\r\n", "\x1a\r\n"},
+		w:       peerW,
+	}
+	go p.serve(peerR)
+
+	d, err := eiscp.NewSerialReceiver(port{Reader: devR, Writer: devW, closers: []io.Closer{devR, devW}}, persistent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		d.Close()
+		peerW.Close()
+		peerR.Close()
+	})
+	return p, d
+}
+
+func (p *serialPeer) serve(r io.Reader) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\r')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) < 5 || line[0] != '!' {
+			continue
+		}
+		code, value := line[2:5], line[5:]
+
+		p.mu.Lock()
+		if value == "QSTN" {
+			v, ok := p.state[code]
+			if !ok {
+				v = "N/A"
+			}
+			value = v
+		} else {
+			p.state[code] = value
+		}
+		p.mu.Unlock()
+		p.send(code, value)
+	}
+}
+
+func (p *serialPeer) send(code, value string) {
+	p.mu.Lock()
+	ending := p.endings[p.next%len(p.endings)]
+	p.next++
+	p.mu.Unlock()
+	io.WriteString(p.w, "!1"+code+value+ending)
+}
+
+func TestSerial(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		_, d := serialLine(t, persistent)
+
+		// enough requests to go through every ending more than once
+		for i := 0; i < 3; i++ {
+			if power, err := d.GetPower(); err != nil || !power {
+				t.Errorf("persistent %v: GetPower() = %v, %v", persistent, power, err)
+			}
+			if vol, err := d.SetVolume(uint8(0x21 + i)); err != nil || vol != uint8(0x21+i) {
+				t.Errorf("persistent %v: SetVolume() = %v, %v", persistent, vol, err)
+			}
+			if muted, err := d.GetMute(); err != nil || muted {
+				t.Errorf("persistent %v: GetMute() = %v, %v", persistent, muted, err)
+			}
+		}
+		if _, err := d.SetGetOne("NRI", "QSTN"); err != eiscp.ErrNotAvailable {
+			t.Errorf("persistent %v: NRI error = %v, want ErrNotAvailable", persistent, err)
+		}
+	}
+}
+
+func TestSerialNotifications(t *testing.T) {
+	p, d := serialLine(t, true)
+	msgs, cancel := d.Subscribe(eiscp.Commands("SLI"))
+	defer cancel()
+
+	// wait for the reconnect queries to be answered, then change the input from the front panel
+	if _, err := d.GetPower(); err != nil {
+		t.Fatal(err)
+	}
+	p.send("SLI", "10")
+	timeout := time.After(2 * time.Second)
+	for changed := false; !changed; {
+		select {
+		case msg := <-msgs:
+			changed = msg.Response == "10"
+		case <-timeout:
+			t.Fatal("no SLI message")
+		}
+	}
+	if src := d.State().Source; src != eiscp.Source("10") {
+		t.Errorf("State().Source = %q, want 10", src)
+	}
+}
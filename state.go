@@ -0,0 +1,269 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// State is the device's view of the receiver, kept up to date from every message it hears,
+// whether it was a reply to one of our queries or changed from the front panel or remote
+type State struct {
+	Power             bool
+	Volume            uint8
+	Mute              bool
+	Source            Source
+	ListeningMode     string
+	TunerPreset       string
+	Temperature       uint8 // celsius
+	NetworkPlayStatus NetworkPlayStatus
+	NowPlaying        NowPlaying
+	Zones             map[int]ZoneState // zones 2-4, only those the receiver has reported
+	Updated           time.Time         // when the last message was applied, zero if nothing has been heard yet
+}
+
+// NowPlaying is the track information for the network services
+type NowPlaying struct {
+	Title  string
+	Artist string
+	Album  string
+}
+
+// ZoneState is the state of one of the additional zones
+type ZoneState struct {
+	Power  bool
+	Volume uint8
+	Mute   bool
+	Source Source
+}
+
+// zoneCodes are the command codes for a zone
+type zoneCodes struct {
+	power   string
+	volume  string
+	mute    string
+	source  string
+	tuner   string
+	preset  string
+	tone    string
+	balance string
+}
+
+// zones maps zone number to its commands, the main zone (1) uses PWR/MVL/AMT/SLI
+var zones = map[int]zoneCodes{
+	2: {power: "ZPW", volume: "ZVL", mute: "ZMT", source: "SLZ", tuner: "TUZ", preset: "PRZ", tone: "ZTN", balance: "ZBL"},
+	3: {power: "PW3", volume: "VL3", mute: "MT3", source: "SL3", tuner: "TU3", preset: "PR3", tone: "TN3", balance: "BL3"},
+	4: {power: "PW4", volume: "VL4", mute: "MT4", source: "SL4", tuner: "TU4", preset: "PR4"},
+}
+
+// refreshCommands are queried by Refresh, and after a persistent device (re)connects
+var refreshCommands = []string{
+	"PWR", "MVL", "AMT", "SLI", "LMD", "PRS", "NST", "NTI", "NAT", "NAL",
+	"ZPW", "ZVL", "ZMT", "SLZ",
+}
+
+type liveState struct {
+	mu         sync.Mutex
+	state      State
+	known      map[string]bool // command codes which have been heard at least once
+	details    *NRI            // the last NRI heard, what the model supports
+	levelSteps map[string]bool // level controls which use half dB steps, learned from replies
+	rds        rdsState
+}
+
+// State returns a copy of the receiver's current state, without asking the receiver.
+// Use Refresh to make sure it is complete.
+func (d *Device) State() State {
+	d.live.mu.Lock()
+	defer d.live.mu.Unlock()
+
+	s := d.live.state
+	s.Zones = make(map[int]ZoneState, len(d.live.state.Zones))
+	for n, z := range d.live.state.Zones {
+		s.Zones[n] = z
+	}
+	return s
+}
+
+// Refresh queries the receiver for everything in State and waits for the replies.
+// Commands the model does not support are skipped.
+func (d *Device) Refresh() error {
+	return d.RefreshContext(context.Background())
+}
+
+// RefreshContext is Refresh with a context for cancellation and deadlines
+func (d *Device) RefreshContext(ctx context.Context) error {
+	for _, code := range refreshCommands {
+		_, err := d.SetGetOneContext(ctx, code, "QSTN")
+		if err == nil || errors.Is(err, ErrNotAvailable) || errors.Is(err, ErrTimeout) {
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+// update applies msg to the state and returns an event for each value which changed.
+// The first time a value is heard counts as a change.
+func (l *liveState) update(msg *Message) []Event {
+	if msg.Parsed == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := &l.state
+	first := !l.known[msg.Command]
+
+	var ev Event
+	switch msg.Command {
+	case "NRI":
+		if v, ok := msg.Parsed.(*NRI); ok {
+			l.details = v
+		}
+		return nil
+	case "SWL", "SW2", "CTL":
+		l.learnSteps(msg)
+		return nil
+	case "TUN", "RDS", "FLD":
+		if ev := l.updateRDS(msg); ev != nil {
+			return []Event{ev}
+		}
+		return nil
+	case "PWR":
+		v, ok := msg.Parsed.(bool)
+		if !ok {
+			return nil
+		}
+		if first || v != s.Power {
+			ev = PowerChanged{On: v}
+		}
+		s.Power = v
+	case "MVL":
+		v, ok := msg.Parsed.(uint8)
+		if !ok {
+			return nil
+		}
+		if first || v != s.Volume {
+			ev = VolumeChanged{Level: v}
+		}
+		s.Volume = v
+	case "AMT":
+		v, ok := msg.Parsed.(bool)
+		if !ok {
+			return nil
+		}
+		if first || v != s.Mute {
+			ev = MuteChanged{Muted: v}
+		}
+		s.Mute = v
+	case "SLI":
+		v := Source(msg.Response)
+		if first || v != s.Source {
+			ev = SourceChanged{Source: v}
+		}
+		s.Source = v
+	case "LMD":
+		v, ok := msg.Parsed.(string)
+		if !ok {
+			return nil
+		}
+		if first || v != s.ListeningMode {
+			ev = ListeningModeChanged{Mode: v}
+		}
+		s.ListeningMode = v
+	case "PRS":
+		v, ok := msg.Parsed.(string)
+		if !ok {
+			return nil
+		}
+		if first || v != s.TunerPreset {
+			ev = TunerPresetChanged{Preset: v}
+		}
+		s.TunerPreset = v
+	case "TPD":
+		v, ok := msg.Parsed.(uint8)
+		if !ok {
+			return nil
+		}
+		if first || v != s.Temperature {
+			ev = TemperatureChanged{Celsius: v}
+		}
+		s.Temperature = v
+	case "NST":
+		v, ok := msg.Parsed.(*NetworkPlayStatus)
+		if !ok {
+			return nil
+		}
+		if first || *v != s.NetworkPlayStatus {
+			ev = NetworkPlayStatusChanged{Status: *v}
+		}
+		s.NetworkPlayStatus = *v
+	case "NTI", "NAT", "NAL":
+		np := s.NowPlaying
+		switch msg.Command {
+		case "NTI":
+			np.Title = msg.Response
+		case "NAT":
+			np.Artist = msg.Response
+		case "NAL":
+			np.Album = msg.Response
+		}
+		if first || np != s.NowPlaying {
+			ev = NowPlayingChanged{NowPlaying: np}
+		}
+		s.NowPlaying = np
+	default:
+		var ok bool
+		if ev, ok = l.updateZone(msg, first); !ok {
+			return nil
+		}
+	}
+
+	if l.known == nil {
+		l.known = make(map[string]bool)
+	}
+	l.known[msg.Command] = true
+	s.Updated = time.Now()
+	if ev == nil {
+		return nil
+	}
+	return []Event{ev}
+}
+
+// updateZone applies a zone 2-4 message, returning false if msg is not for a zone
+func (l *liveState) updateZone(msg *Message, first bool) (Event, bool) {
+	for n, codes := range zones {
+		old := l.state.Zones[n]
+		z := old
+
+		switch msg.Command {
+		case codes.power:
+			z.Power = msg.Response == "01"
+		case codes.volume:
+			vol, err := strconv.ParseUint(msg.Response, 16, 8)
+			if err != nil {
+				return nil, false
+			}
+			z.Volume = uint8(vol)
+		case codes.mute:
+			z.Mute = msg.Response == "01"
+		case codes.source:
+			z.Source = Source(msg.Response)
+		default:
+			continue
+		}
+
+		if l.state.Zones == nil {
+			l.state.Zones = make(map[int]ZoneState)
+		}
+		l.state.Zones[n] = z
+		if first || z != old {
+			return ZoneChanged{Zone: n, State: z}, true
+		}
+		return nil, true
+	}
+	return nil, false
+}
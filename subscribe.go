@@ -0,0 +1,171 @@
+package eiscp
+
+import (
+	"sync"
+	"time"
+)
+
+// Filter selects the messages a subscriber receives, nil means everything
+type Filter func(*Message) bool
+
+// Commands is a Filter for the given command codes, e.g. Commands("PWR", "MVL")
+func Commands(codes ...string) Filter {
+	want := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		want[c] = true
+	}
+	return func(m *Message) bool {
+		return want[m.Command]
+	}
+}
+
+// OverflowPolicy says what to do with a message when a subscriber's buffer is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	DropOldest OverflowPolicy = iota // make room by discarding the oldest buffered message
+	DropNewest                       // discard the new message
+	Block                            // wait up to BlockTimeout for room, then discard the new message
+)
+
+// SubscribeOptions configure a subscription, the zero value is usable
+type SubscribeOptions struct {
+	Buffer       int            // channel size, default 50
+	Overflow     OverflowPolicy // default DropOldest
+	BlockTimeout time.Duration  // how long Block waits, default 1 second
+}
+
+const (
+	defaultBuffer       = 50
+	defaultBlockTimeout = time.Second
+)
+
+type subscriber struct {
+	ch     chan Message
+	filter Filter
+	opts   SubscribeOptions
+	mu     sync.Mutex // held while delivering, so cancel cannot close ch under a send
+	closed bool
+}
+
+// Subscribe returns a channel which receives every message matching filter, and a function to cancel
+// the subscription. A slow subscriber loses its oldest messages, it never holds up the connection.
+// The channel is closed when the subscription is cancelled or the Device is closed.
+func (d *Device) Subscribe(filter Filter) (<-chan Message, func()) {
+	return d.SubscribeWithOptions(filter, SubscribeOptions{})
+}
+
+// SubscribeWithOptions is Subscribe with a choice of buffer size and overflow policy
+func (d *Device) SubscribeWithOptions(filter Filter, opts SubscribeOptions) (<-chan Message, func()) {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultBuffer
+	}
+	if opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = defaultBlockTimeout
+	}
+
+	s := &subscriber{
+		ch:     make(chan Message, opts.Buffer),
+		filter: filter,
+		opts:   opts,
+	}
+
+	d.subMu.Lock()
+	if d.subs == nil {
+		d.subs = make(map[*subscriber]bool)
+	}
+	d.subs[s] = true
+	d.subMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			d.subMu.Lock()
+			delete(d.subs, s)
+			d.subMu.Unlock()
+			s.close()
+		})
+	}
+	return s.ch, cancel
+}
+
+// publish hands msg to every interested subscriber
+func (d *Device) publish(msg *Message) {
+	d.subMu.Lock()
+	subs := make([]*subscriber, 0, len(d.subs))
+	for s := range d.subs {
+		subs = append(subs, s)
+	}
+	d.subMu.Unlock()
+
+	for _, s := range subs {
+		if s.filter != nil && !s.filter(msg) {
+			continue
+		}
+		s.deliver(*msg)
+	}
+}
+
+// closeSubscriptions ends every subscription, used when the Device is closed
+func (d *Device) closeSubscriptions() {
+	d.subMu.Lock()
+	subs := d.subs
+	eventSubs := d.eventSubs
+	d.subs = nil
+	d.eventSubs = nil
+	d.subMu.Unlock()
+
+	for s := range subs {
+		s.close()
+	}
+	for s := range eventSubs {
+		s.close()
+	}
+}
+
+func (s *subscriber) deliver(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	switch s.opts.Overflow {
+	case DropNewest:
+		select {
+		case s.ch <- msg:
+		default:
+		}
+	case Block:
+		t := time.NewTimer(s.opts.BlockTimeout)
+		defer t.Stop()
+		select {
+		case s.ch <- msg:
+		case <-t.C:
+			ologger.Printf("subscriber blocked, dropping message: %s\n", msg.Command)
+		}
+	default:
+		for {
+			select {
+			case s.ch <- msg:
+				return
+			default:
+			}
+			// full, discard the oldest and try again
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	}
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,144 @@
+package eiscp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tone is a bass and treble setting, in the receiver's steps either side of flat (0)
+type Tone struct {
+	Bass   int
+	Treble int
+}
+
+// ToneChannel is a group of speakers with its own bass and treble, named by its command code
+type ToneChannel string
+
+// Tone channels
+const (
+	ToneFront        ToneChannel = "TFR"
+	ToneFrontWide    ToneChannel = "TFW"
+	ToneCenter       ToneChannel = "TCT"
+	ToneSurround     ToneChannel = "TSR"
+	ToneSurroundBack ToneChannel = "TSB"
+	ToneHeight       ToneChannel = "THT"
+)
+
+// ToneChannels are all the tone channels, not every model has all of them
+var ToneChannels = []ToneChannel{ToneFront, ToneFrontWide, ToneCenter, ToneSurround, ToneSurroundBack, ToneHeight}
+
+// defaultToneRange is used when the NRI control list does not give one
+var defaultToneRange = controlRange{min: -10, max: 10, step: 2}
+
+// GetTone gets the bass and treble for a tone channel
+func (d *Device) GetTone(ch ToneChannel) (*Tone, error) {
+	return d.GetToneContext(context.Background(), ch)
+}
+
+// GetToneContext is GetTone with a context for cancellation and deadlines
+func (d *Device) GetToneContext(ctx context.Context, ch ToneChannel) (*Tone, error) {
+	return d.setTone(ctx, ch, "QSTN")
+}
+
+// SetBass sets the bass for a tone channel, checked against the Bass control in the NRI control list
+func (d *Device) SetBass(ch ToneChannel, level int) (*Tone, error) {
+	return d.SetBassContext(context.Background(), ch, level)
+}
+
+// SetBassContext is SetBass with a context for cancellation and deadlines
+func (d *Device) SetBassContext(ctx context.Context, ch ToneChannel, level int) (*Tone, error) {
+	r, err := d.controlRange(ctx, "Bass", 1, defaultToneRange)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.check("bass", level); err != nil {
+		return nil, err
+	}
+	return d.setTone(ctx, ch, "B"+formatLevel(level))
+}
+
+// SetTreble sets the treble for a tone channel, checked against the Treble control in the NRI control list
+func (d *Device) SetTreble(ch ToneChannel, level int) (*Tone, error) {
+	return d.SetTrebleContext(context.Background(), ch, level)
+}
+
+// SetTrebleContext is SetTreble with a context for cancellation and deadlines
+func (d *Device) SetTrebleContext(ctx context.Context, ch ToneChannel, level int) (*Tone, error) {
+	r, err := d.controlRange(ctx, "Treble", 1, defaultToneRange)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.check("treble", level); err != nil {
+		return nil, err
+	}
+	return d.setTone(ctx, ch, "T"+formatLevel(level))
+}
+
+func (d *Device) setTone(ctx context.Context, ch ToneChannel, value string) (*Tone, error) {
+	msg, err := d.SetGetOneContext(ctx, string(ch), value)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := msg.Parsed.(*Tone)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
+}
+
+// parseTone parses a tone reply, e.g. "B+2T-4", either half may be missing
+func parseTone(code, r string) (*Tone, error) {
+	var t Tone
+	rest := r
+	for len(rest) > 0 {
+		if len(rest) < 3 {
+			return nil, &ProtocolError{Frame: []byte(code + r), Reason: "invalid tone"}
+		}
+		level, err := parseLevel(rest[1:3])
+		if err != nil {
+			return nil, &ProtocolError{Frame: []byte(code + r), Reason: "invalid tone"}
+		}
+		switch rest[0] {
+		case 'B':
+			t.Bass = level
+		case 'T':
+			t.Treble = level
+		default:
+			return nil, &ProtocolError{Frame: []byte(code + r), Reason: "invalid tone"}
+		}
+		rest = rest[3:]
+	}
+	return &t, nil
+}
+
+// parseLevel parses a signed hex level, e.g. "-A", "00" or "+2"
+func parseLevel(s string) (int, error) {
+	if s == "00" {
+		return 0, nil
+	}
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid level: %s", s)
+	}
+	v, err := strconv.ParseInt(s[1:], 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid level: %s", s)
+	}
+	if s[0] == '-' {
+		v = -v
+	}
+	return int(v), nil
+}
+
+// formatLevel is the inverse of parseLevel
+func formatLevel(v int) string {
+	switch {
+	case v == 0:
+		return "00"
+	case v < 0:
+		return "-" + strings.ToUpper(strconv.FormatInt(int64(-v), 16))
+	default:
+		return "+" + strings.ToUpper(strconv.FormatInt(int64(v), 16))
+	}
+}
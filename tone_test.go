@@ -0,0 +1,153 @@
+package eiscp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestParseTone(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Tone
+		err   bool
+	}{
+		{"B+2T-4", Tone{Bass: 2, Treble: -4}, false},
+		{"B00T00", Tone{}, false},
+		{"B-AT+A", Tone{Bass: -10, Treble: 10}, false},
+		{"B+2", Tone{Bass: 2}, false},
+		{"T-4", Tone{Treble: -4}, false},
+		{"", Tone{}, false},
+		{"B+", Tone{}, true},
+		{"B+2T", Tone{}, true},
+		{"X00", Tone{}, true},
+		{"B+GT00", Tone{}, true},
+		{"B2+T00", Tone{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTone("TFR", tt.value)
+		if (err != nil) != tt.err {
+			t.Errorf("parseTone(%q) error = %v", tt.value, err)
+			continue
+		}
+		if !tt.err && *got != tt.want {
+			t.Errorf("parseTone(%q) = %+v, want %+v", tt.value, *got, tt.want)
+		}
+	}
+}
+
+func TestLevelRoundTrip(t *testing.T) {
+	tests := []struct {
+		v    int
+		want string
+	}{
+		{0, "00"},
+		{2, "+2"},
+		{-4, "-4"},
+		{10, "+A"},
+		{-10, "-A"},
+		{24, "+18"},
+	}
+
+	for _, tt := range tests {
+		s := formatLevel(tt.v)
+		if s != tt.want {
+			t.Errorf("formatLevel(%d) = %q, want %q", tt.v, s, tt.want)
+		}
+		if v, err := parseLevel(s); err != nil || v != tt.v {
+			t.Errorf("parseLevel(%q) = %d, %v, want %d", s, v, err, tt.v)
+		}
+	}
+}
+
+func TestControlRangeCheck(t *testing.T) {
+	tests := []struct {
+		r   controlRange
+		v   int
+		err bool
+	}{
+		{defaultToneRange, 0, false},
+		{defaultToneRange, -10, false},
+		{defaultToneRange, 10, false},
+		{defaultToneRange, 4, false},
+		{defaultToneRange, 3, true},
+		{defaultToneRange, -1, true},
+		{defaultToneRange, 12, true},
+		{defaultToneRange, -12, true},
+		{controlRange{min: -6, max: 6, step: 1}, 3, false},
+		{controlRange{min: -6, max: 6, step: 1}, 7, true},
+		// steps count from the minimum
+		{controlRange{min: -5, max: 5, step: 2}, -1, false},
+		{controlRange{min: -5, max: 5, step: 2}, 0, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.r.check("bass", tt.v); (err != nil) != tt.err {
+			t.Errorf("%+v check(%d) = %v", tt.r, tt.v, err)
+		}
+	}
+}
+
+func TestSetBass(t *testing.T) {
+	nri := eiscptest.DefaultState["NRI"]
+	tests := []struct {
+		name    string
+		control string // replaces the Bass control in the fixture's NRI
+		level   int
+		err     bool
+		want    string
+	}{
+		{"even", "", 4, false, "B+4T00"},
+		{"odd", "", 3, true, ""},
+		{"too high", "", 12, true, ""},
+		{"finer steps", `<control id="Bass" value="1" zone="1" min="-6" max="6" step="1"/>`, 3, false, "B+3T00"},
+		{"narrower range", `<control id="Bass" value="1" zone="1" min="-6" max="6" step="1"/>`, 8, true, ""},
+		{"other zone only", `<control id="Bass" value="1" zone="2" min="-6" max="6" step="1"/>`, 10, false, "B+AT00"},
+	}
+
+	bass := `<control id="Bass" value="1" zone="1" min="-10" max="10" step="2"/>`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := eiscptest.NewServer()
+			defer s.Close()
+			if tt.control != "" {
+				s.SetNRI(strings.Replace(nri, bass, tt.control, 1))
+			}
+			d, err := NewReceiverWithDialer(s.Dial, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.Close()
+
+			tone, err := d.SetBass(ToneFront, tt.level)
+			if (err != nil) != tt.err {
+				t.Fatalf("SetBass(%d) = %+v, %v", tt.level, tone, err)
+			}
+			v, _ := s.Get("TFR")
+			if tt.err && v != "B00T00" {
+				t.Errorf("rejected level sent anyway, TFR is %q", v)
+			}
+			if !tt.err && v != tt.want {
+				t.Errorf("TFR is %q, want %q", v, tt.want)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		s := eiscptest.NewServer()
+		defer s.Close()
+		s.SetNRI(strings.Replace(nri, bass, `<control id="Bass" value="0" zone="1"/>`, 1))
+		d, err := NewReceiverWithDialer(s.Dial, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+
+		if _, err := d.SetBass(ToneFront, 2); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("SetBass() error = %v, want ErrUnsupported", err)
+		}
+	})
+}
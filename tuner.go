@@ -0,0 +1,255 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Band is a tuner band
+type Band string
+
+// Tuner bands
+const (
+	BandFM Band = "FM"
+	BandAM Band = "AM"
+)
+
+// bandSources are the inputs which select each band
+var bandSources = map[Band]Source{
+	BandFM: "24",
+	BandAM: "25",
+}
+
+// Frequency is a tuner frequency
+type Frequency struct {
+	Band Band `json:"band"`
+	KHz  int  `json:"khz"`
+}
+
+// FM returns an FM frequency, e.g. FM(101.1)
+func FM(mhz float64) Frequency {
+	return Frequency{Band: BandFM, KHz: int(mhz*1000 + 0.5)}
+}
+
+// AM returns an AM frequency, e.g. AM(810)
+func AM(khz int) Frequency {
+	return Frequency{Band: BandAM, KHz: khz}
+}
+
+func (f Frequency) String() string {
+	if f.Band == BandFM {
+		return fmt.Sprintf("%.2f MHz", float64(f.KHz)/1000)
+	}
+	return fmt.Sprintf("%d kHz", f.KHz)
+}
+
+// tun is the TUN value for f, FM is in 10 kHz units and AM in kHz
+func (f Frequency) tun() string {
+	if f.Band == BandFM {
+		return fmt.Sprintf("%05d", f.KHz/10)
+	}
+	return fmt.Sprintf("%05d", f.KHz)
+}
+
+// parseTUN parses a TUN reply; the bands do not overlap, so the band is known from the number alone
+func parseTUN(r string) (*Frequency, error) {
+	v, err := strconv.Atoi(r)
+	if err != nil || v <= 0 {
+		return nil, &ProtocolError{Frame: []byte("TUN" + r), Reason: "invalid frequency"}
+	}
+	if v > 2000 {
+		return &Frequency{Band: BandFM, KHz: v * 10}, nil
+	}
+	return &Frequency{Band: BandAM, KHz: v}, nil
+}
+
+// bandRange is the range and step of a band, in kHz
+type bandRange struct {
+	min  int
+	max  int
+	step int
+}
+
+// defaultBandRanges are used when the NRI does not list the tuners, wide enough for every region
+var defaultBandRanges = map[Band]bandRange{
+	BandFM: {min: 76000, max: 108000, step: 10},
+	BandAM: {min: 522, max: 1710, step: 1},
+}
+
+// Tuner controls the AM/FM tuner
+type Tuner struct {
+	d *Device
+}
+
+// Tuner returns a handle for the AM/FM tuner
+func (d *Device) Tuner() *Tuner {
+	return &Tuner{d: d}
+}
+
+// Get gets the current band and frequency
+func (t *Tuner) Get() (*Frequency, error) {
+	return t.GetContext(context.Background())
+}
+
+// GetContext is Get with a context for cancellation and deadlines
+func (t *Tuner) GetContext(ctx context.Context) (*Frequency, error) {
+	return t.tune(ctx, "QSTN")
+}
+
+// SetFrequency tunes to f, e.g. FM(101.1) or AM(810), switching band if need be.
+// f is checked against the band's range and step from the NRI tuner list.
+func (t *Tuner) SetFrequency(f Frequency) (*Frequency, error) {
+	return t.SetFrequencyContext(context.Background(), f)
+}
+
+// SetFrequencyContext is SetFrequency with a context for cancellation and deadlines
+func (t *Tuner) SetFrequencyContext(ctx context.Context, f Frequency) (*Frequency, error) {
+	r, err := t.bandRange(ctx, f.Band)
+	if err != nil {
+		return nil, err
+	}
+	if f.KHz < r.min || f.KHz > r.max {
+		return nil, fmt.Errorf("%s out of range %s to %s", f, Frequency{f.Band, r.min}, Frequency{f.Band, r.max})
+	}
+	if (f.KHz-r.min)%r.step != 0 {
+		return nil, fmt.Errorf("%s is not a multiple of %d kHz from %s", f, r.step, Frequency{f.Band, r.min})
+	}
+
+	if _, err := t.SetBandContext(ctx, f.Band); err != nil {
+		return nil, err
+	}
+	return t.tune(ctx, f.tun())
+}
+
+// SetBand switches the tuner to a band by selecting its input
+func (t *Tuner) SetBand(b Band) (*Frequency, error) {
+	return t.SetBandContext(context.Background(), b)
+}
+
+// SetBandContext is SetBand with a context for cancellation and deadlines
+func (t *Tuner) SetBandContext(ctx context.Context, b Band) (*Frequency, error) {
+	src, ok := bandSources[b]
+	if !ok {
+		return nil, fmt.Errorf("band %s: %w", b, ErrUnsupported)
+	}
+	current, err := t.d.GetSourceByCodeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current != src {
+		if _, err := t.d.SetSourceContext(ctx, src); err != nil {
+			return nil, err
+		}
+	}
+	return t.GetContext(ctx)
+}
+
+// Step tunes to the next frequency up or down the band, by the band's step, wrapping at the ends
+func (t *Tuner) Step(up bool) (*Frequency, error) {
+	return t.StepContext(context.Background(), up)
+}
+
+// StepContext is Step with a context for cancellation and deadlines
+func (t *Tuner) StepContext(ctx context.Context, up bool) (*Frequency, error) {
+	f, err := t.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := t.bandRange(ctx, f.Band)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *f
+	if up {
+		next.KHz += r.step
+	} else {
+		next.KHz -= r.step
+	}
+	// snap back onto the band's raster, in case the receiver was tuned off it
+	next.KHz = r.min + (next.KHz-r.min)/r.step*r.step
+	switch {
+	case next.KHz > r.max:
+		next.KHz = r.min
+	case next.KHz < r.min:
+		next.KHz = r.min + (r.max-r.min)/r.step*r.step
+	}
+	return t.tune(ctx, next.tun())
+}
+
+// Seek sends TUN UP or DOWN. With the receiver's tuning mode on auto it seeks to the next station,
+// otherwise it steps like Step.
+func (t *Tuner) Seek(up bool) (*Frequency, error) {
+	return t.SeekContext(context.Background(), up)
+}
+
+// SeekContext is Seek with a context for cancellation and deadlines
+func (t *Tuner) SeekContext(ctx context.Context, up bool) (*Frequency, error) {
+	if up {
+		return t.tune(ctx, "UP")
+	}
+	return t.tune(ctx, "DOWN")
+}
+
+func (t *Tuner) tune(ctx context.Context, value string) (*Frequency, error) {
+	msg, err := t.d.SetGetOneContext(ctx, "TUN", value)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := msg.Parsed.(*Frequency)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
+}
+
+// bandRange looks up a band in the NRI tuner list, falling back to defaultBandRanges
+func (t *Tuner) bandRange(ctx context.Context, b Band) (bandRange, error) {
+	r, ok := defaultBandRanges[b]
+	if !ok {
+		return r, fmt.Errorf("band %s: %w", b, ErrUnsupported)
+	}
+
+	nri, err := t.d.details(ctx)
+	if errors.Is(err, ErrNotAvailable) || errors.Is(err, ErrTimeout) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+
+	for _, tn := range nri.Device.Tuners.Tuner {
+		if tn.Band != string(b) {
+			continue
+		}
+		if v, ok := nriKHz(b, tn.Min); ok {
+			r.min = v
+		}
+		if v, ok := nriKHz(b, tn.Max); ok {
+			r.max = v
+		}
+		if v, ok := nriKHz(b, tn.Step); ok {
+			r.step = v
+		}
+		return r, nil
+	}
+	if len(nri.Device.Tuners.Tuner) > 0 {
+		return r, fmt.Errorf("band %s: %w", b, ErrUnsupported)
+	}
+	return r, nil
+}
+
+// nriKHz parses a frequency or step from the NRI, which gives FM in MHz (e.g. "87.5", "0.2") and AM in kHz
+func nriKHz(b Band, s string) (int, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	if b == BandFM {
+		return int(v*1000 + 0.5), true
+	}
+	return int(v + 0.5), true
+}
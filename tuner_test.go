@@ -0,0 +1,76 @@
+package eiscp
+
+import (
+	"testing"
+
+	"github.com/cloudkucooland/go-onkyo/eiscptest"
+)
+
+func TestNRIKHz(t *testing.T) {
+	tests := []struct {
+		band Band
+		s    string
+		want int
+		ok   bool
+	}{
+		{BandFM, "87.5", 87500, true},
+		{BandFM, "107.9", 107900, true},
+		{BandFM, "0.2", 200, true},
+		{BandFM, "0.05", 50, true},
+		{BandAM, "530", 530, true},
+		{BandAM, "9", 9, true},
+		{BandFM, "", 0, false},
+		{BandAM, "0", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := nriKHz(tt.band, tt.s)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("nriKHz(%s, %q) = %d, %v, want %d, %v", tt.band, tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSetFrequency(t *testing.T) {
+	s := eiscptest.NewServer()
+	defer s.Close()
+	d, err := NewReceiverWithDialer(s.Dial, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	tuner := d.Tuner()
+
+	// the fixture's NRI lists FM from 87.5 to 107.9 MHz in 0.2 MHz steps, as North American models do
+	tests := []struct {
+		f   Frequency
+		err bool
+		tun string
+	}{
+		{FM(101.1), false, "10110"},
+		{FM(87.5), false, "08750"},
+		{FM(107.9), false, "10790"},
+		{FM(101.2), true, ""}, // off the 200 kHz raster
+		{FM(108.1), true, ""}, // above the band
+		{FM(76.1), true, ""},  // only in Japan
+		{AM(810), false, "00810"},
+		{AM(1720), true, ""},
+		{AM(815), true, ""},
+	}
+
+	for _, tt := range tests {
+		before, _ := s.Get("TUN")
+		_, err := tuner.SetFrequency(tt.f)
+		if (err != nil) != tt.err {
+			t.Errorf("SetFrequency(%s) error = %v", tt.f, err)
+			continue
+		}
+		after, _ := s.Get("TUN")
+		if tt.err && after != before {
+			t.Errorf("SetFrequency(%s) was sent, TUN is %s", tt.f, after)
+		}
+		if !tt.err && after != tt.tun {
+			t.Errorf("SetFrequency(%s) sent TUN %s, want %s", tt.f, after, tt.tun)
+		}
+	}
+}
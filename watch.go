@@ -0,0 +1,204 @@
+package eiscp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/brutella/dnssd"
+)
+
+// DiscoveryEventType says what happened to a receiver
+type DiscoveryEventType int
+
+// Discovery events
+const (
+	DeviceAdded DiscoveryEventType = iota
+	DeviceUpdated
+	DeviceRemoved
+)
+
+func (t DiscoveryEventType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceUpdated:
+		return "updated"
+	case DeviceRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// DiscoveryEvent is sent by Watch when a receiver appears, changes address, or goes away
+type DiscoveryEvent struct {
+	Type   DiscoveryEventType
+	Device DiscoveredDevice
+}
+
+// how often Watch repeats the eISCP broadcast, and how many missed replies before a device is considered gone
+const (
+	watchInterval = time.Minute
+	watchMisses   = 3
+)
+
+type watcher struct {
+	mu     sync.Mutex
+	known  map[string]DiscoveredDevice
+	mdns   map[string]bool      // devices which are also announced via mDNS, they go away when mDNS says so
+	seen   map[string]time.Time // last eISCP reply
+	events chan DiscoveryEvent
+	ctx    context.Context
+}
+
+// Watch keeps browsing mDNS, and repeats the eISCP broadcast every minute, sending an event
+// each time a receiver is added, changes address, or goes away. Devices are keyed by MAC address.
+// The channel is closed when ctx is done.
+func Watch(ctx context.Context) <-chan DiscoveryEvent {
+	w := watcher{
+		known:  make(map[string]DiscoveredDevice),
+		mdns:   make(map[string]bool),
+		seen:   make(map[string]time.Time),
+		events: make(chan DiscoveryEvent, 16),
+		ctx:    ctx,
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		add := func(e dnssd.BrowseEntry) {
+			if dd, ok := parseBrowseEntry(e); ok && dd.isOnkyo() {
+				w.update(dd, true)
+			}
+		}
+		rmv := func(e dnssd.BrowseEntry) {
+			dd, _ := parseBrowseEntry(e)
+			w.remove(dd.MAC)
+		}
+		if err := dnssd.LookupType(ctx, "_raop._tcp.local.", add, rmv); err != nil && ctx.Err() == nil {
+			ologger.Printf("discovery: %v\n", err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for {
+			sweep, cancel := context.WithTimeout(ctx, 5*time.Second)
+			start := time.Now()
+			if err := discoverECN(sweep, BroadcastAddr, func(dd DiscoveredDevice) { w.update(dd, false) }); err != nil {
+				ologger.Printf("discovery: %v\n", err)
+			}
+			cancel()
+			w.expire(start.Add(-watchMisses * watchInterval))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchInterval):
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(w.events)
+	}()
+	return w.events
+}
+
+func (w *watcher) update(dd DiscoveredDevice, fromMDNS bool) {
+	if dd.MAC == "" {
+		return
+	}
+
+	w.mu.Lock()
+	if fromMDNS {
+		w.mdns[dd.MAC] = true
+	} else {
+		w.seen[dd.MAC] = time.Now()
+	}
+	existing, ok := w.known[dd.MAC]
+	if !ok {
+		w.known[dd.MAC] = dd
+		w.mu.Unlock()
+		w.send(DeviceAdded, dd)
+		return
+	}
+	updated := existing
+	updated.merge(dd)
+	if dd.Host != "" {
+		updated.Host = dd.Host
+	}
+	w.known[dd.MAC] = updated
+	w.mu.Unlock()
+
+	if updated != existing {
+		w.send(DeviceUpdated, updated)
+	}
+}
+
+func (w *watcher) remove(mac string) {
+	w.mu.Lock()
+	dd, ok := w.known[mac]
+	if ok {
+		delete(w.known, mac)
+		delete(w.mdns, mac)
+		delete(w.seen, mac)
+	}
+	w.mu.Unlock()
+
+	if ok {
+		w.send(DeviceRemoved, dd)
+	}
+}
+
+// expire removes devices only found by the eISCP broadcast which have not replied since before
+func (w *watcher) expire(before time.Time) {
+	var gone []string
+	w.mu.Lock()
+	for mac, last := range w.seen {
+		if !w.mdns[mac] && last.Before(before) {
+			gone = append(gone, mac)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, mac := range gone {
+		w.remove(mac)
+	}
+}
+
+func (w *watcher) send(t DiscoveryEventType, dd DiscoveredDevice) {
+	select {
+	case w.events <- DiscoveryEvent{Type: t, Device: dd}:
+	case <-w.ctx.Done():
+	}
+}
+
+// Follow watches the network and re-targets d.Host when the receiver with d's MAC address changes IP,
+// e.g. after a DHCP lease renewal. The next (re)connect uses the new address. It runs until ctx is done.
+func (d *Device) Follow(ctx context.Context) error {
+	mac := d.mac()
+	if mac == "" {
+		nri, err := d.GetDetailsContext(ctx)
+		if err != nil {
+			return err
+		}
+		mac = normalizeMAC(nri.Device.MacAddress)
+		d.setMAC(mac)
+	}
+
+	for ev := range Watch(ctx) {
+		if ev.Type == DeviceRemoved || ev.Device.MAC != mac {
+			continue
+		}
+		addr := ev.Device.Address()
+		if addr != d.host() {
+			ologger.Printf("receiver %s moved to %s\n", mac, addr)
+			d.setHost(addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package eiscp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Zone controls one of the receiver's additional zones
+type Zone struct {
+	d      *Device
+	n      int
+	codes  zoneCodes
+	volmax uint8 // from the NRI zone list, 0 if unknown
+}
+
+// zoneToneRange is how far zone bass, treble and balance go either side of 0, in steps of 2
+const zoneToneRange = 10
+
+// Zone2 returns a handle for Zone 2, without checking the model has one; Zone(2) does check
+func (d *Device) Zone2() *Zone {
+	return &Zone{d: d, n: 2, codes: zones[2]}
+}
+
+// Zone returns a handle for zone 2, 3 or 4. Zones the receiver's NRI zone list says are absent
+// are refused with ErrUnsupported, and SetVolume is limited to the zone's volmax.
+// Receivers which do not answer NRI get an unchecked handle.
+func (d *Device) Zone(n int) (*Zone, error) {
+	return d.ZoneContext(context.Background(), n)
+}
+
+// ZoneContext is Zone with a context for cancellation and deadlines
+func (d *Device) ZoneContext(ctx context.Context, n int) (*Zone, error) {
+	codes, ok := zones[n]
+	if !ok {
+		return nil, fmt.Errorf("zone %d: %w", n, ErrUnsupported)
+	}
+	z := &Zone{d: d, n: n, codes: codes}
+
+	nri, err := d.details(ctx)
+	if errors.Is(err, ErrNotAvailable) || errors.Is(err, ErrTimeout) {
+		return z, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(nri.Device.ZoneList.Zone) == 0 {
+		return z, nil
+	}
+
+	for _, zi := range nri.Device.ZoneList.Zone {
+		if zi.ID != strconv.Itoa(n) {
+			continue
+		}
+		if zi.Value != "1" {
+			break
+		}
+		z.volmax = volmax(zi.Volmax, zi.Volstep)
+		return z, nil
+	}
+	return nil, fmt.Errorf("zone %d: %w", n, ErrUnsupported)
+}
+
+// volmax converts a zone's volmax to a volume level; a volstep of 1 means half steps, which doubles the level
+func volmax(limit, step string) uint8 {
+	v, err := strconv.Atoi(limit)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	if step == "1" {
+		v *= 2
+	}
+	if v > 0xFF {
+		return 0
+	}
+	return uint8(v)
+}
+
+// Number is the zone number, 2-4
+func (z *Zone) Number() int {
+	return z.n
+}
+
+// SetPower turns the zone on or off
+func (z *Zone) SetPower(on bool) (bool, error) {
+	return z.SetPowerContext(context.Background(), on)
+}
+
+// SetPowerContext is SetPower with a context for cancellation and deadlines
+func (z *Zone) SetPowerContext(ctx context.Context, on bool) (bool, error) {
+	state := "00"
+	if on {
+		state = "01"
+	}
+	return z.getBool(ctx, z.codes.power, state)
+}
+
+// GetPower gets the zone's power state
+func (z *Zone) GetPower() (bool, error) {
+	return z.GetPowerContext(context.Background())
+}
+
+// GetPowerContext is GetPower with a context for cancellation and deadlines
+func (z *Zone) GetPowerContext(ctx context.Context) (bool, error) {
+	return z.getBool(ctx, z.codes.power, "QSTN")
+}
+
+// SetVolume sets the zone's volume, limited to the zone's maximum when it is known
+func (z *Zone) SetVolume(level uint8) (uint8, error) {
+	return z.SetVolumeContext(context.Background(), level)
+}
+
+// SetVolumeContext is SetVolume with a context for cancellation and deadlines
+func (z *Zone) SetVolumeContext(ctx context.Context, level uint8) (uint8, error) {
+	if z.volmax > 0 && level > z.volmax {
+		level = z.volmax
+	}
+	return z.getVolume(ctx, fmt.Sprintf("%02X", level))
+}
+
+// GetVolume gets the zone's volume
+func (z *Zone) GetVolume() (uint8, error) {
+	return z.GetVolumeContext(context.Background())
+}
+
+// GetVolumeContext is GetVolume with a context for cancellation and deadlines
+func (z *Zone) GetVolumeContext(ctx context.Context) (uint8, error) {
+	return z.getVolume(ctx, "QSTN")
+}
+
+// VolumeUp raises the zone's volume by one step
+func (z *Zone) VolumeUp() (uint8, error) {
+	return z.VolumeUpContext(context.Background())
+}
+
+// VolumeUpContext is VolumeUp with a context for cancellation and deadlines
+func (z *Zone) VolumeUpContext(ctx context.Context) (uint8, error) {
+	return z.getVolume(ctx, "UP")
+}
+
+// VolumeDown lowers the zone's volume by one step
+func (z *Zone) VolumeDown() (uint8, error) {
+	return z.VolumeDownContext(context.Background())
+}
+
+// VolumeDownContext is VolumeDown with a context for cancellation and deadlines
+func (z *Zone) VolumeDownContext(ctx context.Context) (uint8, error) {
+	return z.getVolume(ctx, "DOWN")
+}
+
+// SetMute mutes or unmutes the zone
+func (z *Zone) SetMute(mute bool) (bool, error) {
+	return z.SetMuteContext(context.Background(), mute)
+}
+
+// SetMuteContext is SetMute with a context for cancellation and deadlines
+func (z *Zone) SetMuteContext(ctx context.Context, mute bool) (bool, error) {
+	state := "00"
+	if mute {
+		state = "01"
+	}
+	return z.getBool(ctx, z.codes.mute, state)
+}
+
+// GetMute gets the zone's mute state
+func (z *Zone) GetMute() (bool, error) {
+	return z.GetMuteContext(context.Background())
+}
+
+// GetMuteContext is GetMute with a context for cancellation and deadlines
+func (z *Zone) GetMuteContext(ctx context.Context) (bool, error) {
+	return z.getBool(ctx, z.codes.mute, "QSTN")
+}
+
+// SetSource selects the zone's input
+func (z *Zone) SetSource(source Source) (Source, error) {
+	return z.SetSourceContext(context.Background(), source)
+}
+
+// SetSourceContext is SetSource with a context for cancellation and deadlines
+func (z *Zone) SetSourceContext(ctx context.Context, source Source) (Source, error) {
+	msg, err := z.d.SetGetOneContext(ctx, z.codes.source, string(source))
+	if err != nil {
+		return "", err
+	}
+	return Source(msg.Response), nil
+}
+
+// GetSource gets the zone's input. Use SourceToName to get readable name
+func (z *Zone) GetSource() (Source, error) {
+	return z.GetSourceContext(context.Background())
+}
+
+// GetSourceContext is GetSource with a context for cancellation and deadlines
+func (z *Zone) GetSourceContext(ctx context.Context) (Source, error) {
+	return z.SetSourceContext(ctx, "QSTN")
+}
+
+// SetTuner tunes the zone's tuner, e.g. "10110" for 101.10 MHz FM or "00999" for 999 kHz AM
+func (z *Zone) SetTuner(freq string) (string, error) {
+	return z.SetTunerContext(context.Background(), freq)
+}
+
+// SetTunerContext is SetTuner with a context for cancellation and deadlines
+func (z *Zone) SetTunerContext(ctx context.Context, freq string) (string, error) {
+	return z.getString(ctx, z.codes.tuner, freq)
+}
+
+// GetTuner gets the zone's tuner frequency
+func (z *Zone) GetTuner() (string, error) {
+	return z.GetTunerContext(context.Background())
+}
+
+// GetTunerContext is GetTuner with a context for cancellation and deadlines
+func (z *Zone) GetTunerContext(ctx context.Context) (string, error) {
+	return z.getString(ctx, z.codes.tuner, "QSTN")
+}
+
+// SetPreset selects a tuner preset for the zone, as two hex digits e.g. "01"
+func (z *Zone) SetPreset(p string) (string, error) {
+	return z.SetPresetContext(context.Background(), p)
+}
+
+// SetPresetContext is SetPreset with a context for cancellation and deadlines
+func (z *Zone) SetPresetContext(ctx context.Context, p string) (string, error) {
+	return z.getString(ctx, z.codes.preset, p)
+}
+
+// GetPreset gets the zone's tuner preset
+func (z *Zone) GetPreset() (string, error) {
+	return z.GetPresetContext(context.Background())
+}
+
+// GetPresetContext is GetPreset with a context for cancellation and deadlines
+func (z *Zone) GetPresetContext(ctx context.Context) (string, error) {
+	return z.getString(ctx, z.codes.preset, "QSTN")
+}
+
+// SetBass sets the zone's bass, -10 to +10 in steps of 2. Zone 4 has no tone control
+func (z *Zone) SetBass(level int) (*Tone, error) {
+	return z.SetBassContext(context.Background(), level)
+}
+
+// SetBassContext is SetBass with a context for cancellation and deadlines
+func (z *Zone) SetBassContext(ctx context.Context, level int) (*Tone, error) {
+	if level < -zoneToneRange || level > zoneToneRange {
+		return nil, fmt.Errorf("bass out of range: %d", level)
+	}
+	return z.getTone(ctx, "B"+formatLevel(level))
+}
+
+// SetTreble sets the zone's treble, -10 to +10 in steps of 2. Zone 4 has no tone control
+func (z *Zone) SetTreble(level int) (*Tone, error) {
+	return z.SetTrebleContext(context.Background(), level)
+}
+
+// SetTrebleContext is SetTreble with a context for cancellation and deadlines
+func (z *Zone) SetTrebleContext(ctx context.Context, level int) (*Tone, error) {
+	if level < -zoneToneRange || level > zoneToneRange {
+		return nil, fmt.Errorf("treble out of range: %d", level)
+	}
+	return z.getTone(ctx, "T"+formatLevel(level))
+}
+
+// GetTone gets the zone's bass and treble
+func (z *Zone) GetTone() (*Tone, error) {
+	return z.GetToneContext(context.Background())
+}
+
+// GetToneContext is GetTone with a context for cancellation and deadlines
+func (z *Zone) GetToneContext(ctx context.Context) (*Tone, error) {
+	return z.getTone(ctx, "QSTN")
+}
+
+// SetBalance sets the zone's balance, -10 (left) to +10 (right) in steps of 2
+func (z *Zone) SetBalance(level int) (int, error) {
+	return z.SetBalanceContext(context.Background(), level)
+}
+
+// SetBalanceContext is SetBalance with a context for cancellation and deadlines
+func (z *Zone) SetBalanceContext(ctx context.Context, level int) (int, error) {
+	if level < -zoneToneRange || level > zoneToneRange {
+		return 0, fmt.Errorf("balance out of range: %d", level)
+	}
+	return z.getBalance(ctx, formatLevel(level))
+}
+
+// GetBalance gets the zone's balance
+func (z *Zone) GetBalance() (int, error) {
+	return z.GetBalanceContext(context.Background())
+}
+
+// GetBalanceContext is GetBalance with a context for cancellation and deadlines
+func (z *Zone) GetBalanceContext(ctx context.Context) (int, error) {
+	return z.getBalance(ctx, "QSTN")
+}
+
+// query sends value for code, failing if this zone has no such command
+func (z *Zone) query(ctx context.Context, code, value string) (*Message, error) {
+	if code == "" {
+		return nil, fmt.Errorf("zone %d: %w", z.n, ErrUnsupported)
+	}
+	return z.d.SetGetOneContext(ctx, code, value)
+}
+
+func (z *Zone) getBool(ctx context.Context, code, value string) (bool, error) {
+	msg, err := z.query(ctx, code, value)
+	if err != nil {
+		return false, err
+	}
+	v, ok := msg.Parsed.(bool)
+	if !ok {
+		return false, unexpected(msg)
+	}
+	return v, nil
+}
+
+func (z *Zone) getVolume(ctx context.Context, value string) (uint8, error) {
+	msg, err := z.query(ctx, z.codes.volume, value)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := msg.Parsed.(uint8)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
+}
+
+func (z *Zone) getString(ctx context.Context, code, value string) (string, error) {
+	msg, err := z.query(ctx, code, value)
+	if err != nil {
+		return "", err
+	}
+	v, ok := msg.Parsed.(string)
+	if !ok {
+		return "", unexpected(msg)
+	}
+	return v, nil
+}
+
+func (z *Zone) getTone(ctx context.Context, value string) (*Tone, error) {
+	msg, err := z.query(ctx, z.codes.tone, value)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := msg.Parsed.(*Tone)
+	if !ok {
+		return nil, unexpected(msg)
+	}
+	return v, nil
+}
+
+func (z *Zone) getBalance(ctx context.Context, value string) (int, error) {
+	msg, err := z.query(ctx, z.codes.balance, value)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := msg.Parsed.(int)
+	if !ok {
+		return 0, unexpected(msg)
+	}
+	return v, nil
+}